@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"flag"
 	"log"
 	"net/http"
+	_ "time/tzdata"
 
 	"crypto-alerts/internal/config"
 	"crypto-alerts/internal/handler"
+	"crypto-alerts/internal/scheduler"
 )
 
 const (
@@ -27,6 +30,8 @@ func main() {
 		log.Fatalf("Failed to initialize API: %v", err)
 	}
 
+	scheduler.New(api.Jobs()...).Start(context.Background())
+
 	mux := api.SetupRoutes()
 	server := &http.Server{
 		Addr:    ":" + *port,
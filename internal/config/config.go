@@ -4,8 +4,24 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 )
 
+// defaultDigestHour is the hour daily and weekly digests are sent at, in
+// each subscriber's time zone.
+const defaultDigestHour = 8
+
+// Outbox delivery is retried after defaultOutboxBackoffSeconds, doubling on
+// each attempt, until defaultOutboxMaxAttempts is reached.
+const (
+	defaultOutboxMaxAttempts    = 6
+	defaultOutboxBackoffSeconds = 60
+)
+
+// defaultOptInExpiryHours is how long a new address has to confirm its
+// subscriptions before they expire.
+const defaultOptInExpiryHours = 72
+
 type CryptoConfig struct {
 	Symbol string `json:"symbol"`
 
@@ -38,11 +54,74 @@ type DatabaseConfig struct {
 	Table    string `json:"table"`
 }
 
+// SMTP TLS modes. Opportunistic upgrades with STARTTLS when the server
+// offers it, which is what net/smtp.SendMail did.
+const (
+	SMTPTLSImplicit      = "implicit"
+	SMTPTLSStartTLS      = "starttls"
+	SMTPTLSOpportunistic = "opportunistic"
+	SMTPTLSNone          = "none"
+)
+
+const (
+	SMTPAuthPlain = "plain"
+	SMTPAuthLogin = "login"
+	SMTPAuthNone  = "none"
+)
+
 type SMTPConfig struct {
 	Host     string `json:"host"`
 	Port     int    `json:"port"`
 	Username string `json:"username"`
 	Password string `json:"password"`
+	TLSMode  string `json:"tls_mode"`
+	Auth     string `json:"auth"`
+
+	// Sender shown to recipients; From defaults to Username
+	From     string `json:"from"`
+	FromName string `json:"from_name"`
+}
+
+type NotificationConfig struct {
+	GroupBySymbol bool `json:"group_by_symbol"`
+
+	// Directory whose *.html files override the embedded email templates
+	TemplateDir string `json:"template_dir"`
+}
+
+// ActionLinkConfig enables the signed snooze and unsubscribe links in alert
+// emails. Links are left out when either field is empty.
+type ActionLinkConfig struct {
+	// PUBLIC_BASE_URL: address the links point to, e.g. https://alerts.example.com
+	BaseURL string `json:"base_url"`
+	// ACTION_LINK_SECRET: key the link tokens are signed with
+	Secret string `json:"secret"`
+}
+
+type OutboxConfig struct {
+	MaxAttempts    int `json:"max_attempts"`
+	BackoffSeconds int `json:"backoff_seconds"`
+}
+
+// AdminConfig protects the admin endpoints, which are disabled while Token
+// is empty.
+type AdminConfig struct {
+	Token string `json:"token"`
+}
+
+// OptInConfig controls double opt-in, which is off unless OPTIN_REQUIRED is
+// "true". Requiring it needs action links to be enabled, since the
+// confirmation link is signed with the same secret.
+type OptInConfig struct {
+	// OPTIN_REQUIRED: keep new addresses pending until they confirm
+	Required bool `json:"required"`
+	// OPTIN_EXPIRY_HOURS: time to confirm before pending alerts expire,
+	// 72 by default
+	ExpiryHours int `json:"expiry_hours"`
+}
+
+type DigestConfig struct {
+	Hour int `json:"hour"`
 }
 
 type APIProviderConfig struct {
@@ -56,9 +135,15 @@ type APIConfig struct {
 }
 
 type Config struct {
-	API      APIConfig      `json:"api"`
-	Database DatabaseConfig `json:"database"`
-	SMTP     SMTPConfig     `json:"smtp"`
+	API          APIConfig          `json:"api"`
+	Database     DatabaseConfig     `json:"database"`
+	SMTP         SMTPConfig         `json:"smtp"`
+	Digest       DigestConfig       `json:"digest"`
+	Notification NotificationConfig `json:"notification"`
+	ActionLink   ActionLinkConfig   `json:"action_link"`
+	OptIn        OptInConfig        `json:"opt_in"`
+	Outbox       OutboxConfig       `json:"outbox"`
+	Admin        AdminConfig        `json:"admin"`
 }
 
 func LoadConfig() (*Config, error) {
@@ -87,6 +172,32 @@ func LoadConfig() (*Config, error) {
 			Port:     parseEnvInt("SMTP_PORT"),
 			Username: os.Getenv("SMTP_USERNAME"),
 			Password: os.Getenv("SMTP_PASSWORD"),
+			TLSMode:  strings.ToLower(envDefault("SMTP_TLS_MODE", SMTPTLSOpportunistic)),
+			Auth:     strings.ToLower(envDefault("SMTP_AUTH", SMTPAuthPlain)),
+			From:     envDefault("SMTP_FROM", os.Getenv("SMTP_USERNAME")),
+			FromName: os.Getenv("SMTP_FROM_NAME"),
+		},
+		Digest: DigestConfig{
+			Hour: parseEnvIntDefault("DIGEST_HOUR", defaultDigestHour),
+		},
+		Notification: NotificationConfig{
+			GroupBySymbol: os.Getenv("NOTIFY_GROUP_BY_SYMBOL") == "true",
+			TemplateDir:   os.Getenv("EMAIL_TEMPLATE_DIR"),
+		},
+		ActionLink: ActionLinkConfig{
+			BaseURL: os.Getenv("PUBLIC_BASE_URL"),
+			Secret:  os.Getenv("ACTION_LINK_SECRET"),
+		},
+		OptIn: OptInConfig{
+			Required:    os.Getenv("OPTIN_REQUIRED") == "true",
+			ExpiryHours: parseEnvIntDefault("OPTIN_EXPIRY_HOURS", defaultOptInExpiryHours),
+		},
+		Outbox: OutboxConfig{
+			MaxAttempts:    parseEnvIntDefault("OUTBOX_MAX_ATTEMPTS", defaultOutboxMaxAttempts),
+			BackoffSeconds: parseEnvIntDefault("OUTBOX_BACKOFF_SECONDS", defaultOutboxBackoffSeconds),
+		},
+		Admin: AdminConfig{
+			Token: os.Getenv("ADMIN_TOKEN"),
 		},
 	}
 
@@ -97,6 +208,20 @@ func LoadConfig() (*Config, error) {
 	return config, nil
 }
 
+func envDefault(key string, defaultValue string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return defaultValue
+}
+
+func parseEnvIntDefault(key string, defaultValue int) int {
+	if os.Getenv(key) == "" {
+		return defaultValue
+	}
+	return parseEnvInt(key)
+}
+
 func parseEnvInt(key string) int {
 	val := os.Getenv(key)
 	i, err := strconv.Atoi(val)
@@ -138,11 +263,40 @@ func validateConfig(config *Config) error {
 	if config.SMTP.Port == 0 {
 		return fmt.Errorf("SMTP port is required (SMTP_PORT)")
 	}
-	if config.SMTP.Username == "" {
-		return fmt.Errorf("SMTP username is required (SMTP_USERNAME)")
+	switch config.SMTP.TLSMode {
+	case SMTPTLSImplicit, SMTPTLSStartTLS, SMTPTLSOpportunistic, SMTPTLSNone:
+	default:
+		return fmt.Errorf("SMTP TLS mode must be implicit, starttls, opportunistic or none (SMTP_TLS_MODE)")
+	}
+	switch config.SMTP.Auth {
+	case SMTPAuthPlain, SMTPAuthLogin:
+		if config.SMTP.Username == "" {
+			return fmt.Errorf("SMTP username is required (SMTP_USERNAME)")
+		}
+		if config.SMTP.Password == "" {
+			return fmt.Errorf("SMTP password is required (SMTP_PASSWORD)")
+		}
+	case SMTPAuthNone:
+	default:
+		return fmt.Errorf("SMTP auth must be plain, login or none (SMTP_AUTH)")
+	}
+	if config.SMTP.From == "" {
+		return fmt.Errorf("SMTP sender address is required (SMTP_FROM)")
+	}
+	if config.Digest.Hour < 0 || config.Digest.Hour > 23 {
+		return fmt.Errorf("digest hour must be between 0 and 23 (DIGEST_HOUR)")
+	}
+	if config.OptIn.Required && (config.ActionLink.BaseURL == "" || config.ActionLink.Secret == "") {
+		return fmt.Errorf("double opt-in (OPTIN_REQUIRED) requires PUBLIC_BASE_URL and ACTION_LINK_SECRET")
+	}
+	if config.OptIn.ExpiryHours <= 0 {
+		return fmt.Errorf("opt-in expiry must be a positive number of hours (OPTIN_EXPIRY_HOURS)")
+	}
+	if config.Outbox.MaxAttempts <= 0 {
+		return fmt.Errorf("outbox max attempts must be positive (OUTBOX_MAX_ATTEMPTS)")
 	}
-	if config.SMTP.Password == "" {
-		return fmt.Errorf("SMTP password is required (SMTP_PASSWORD)")
+	if config.Outbox.BackoffSeconds <= 0 {
+		return fmt.Errorf("outbox backoff must be a positive number of seconds (OUTBOX_BACKOFF_SECONDS)")
 	}
 
 	return nil
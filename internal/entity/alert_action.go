@@ -0,0 +1,31 @@
+package entity
+
+import "time"
+
+// Actions a user can take straight from an alert email.
+const (
+	AlertActionSnooze1h          = "snooze_1h"
+	AlertActionSnooze24h         = "snooze_24h"
+	AlertActionDisableRule       = "disable_rule"
+	AlertActionUnsubscribeSymbol = "unsubscribe_symbol"
+	AlertActionUnsubscribeAll    = "unsubscribe"
+	AlertActionConfirmEmail      = "confirm_email"
+)
+
+var AlertActionSnoozes = map[string]time.Duration{
+	AlertActionSnooze1h:  time.Hour,
+	AlertActionSnooze24h: 24 * time.Hour,
+}
+
+// AlertAction is the payload of a signed action link. RuleID is only set
+// when the action targets a single window or target price rule, and Locale
+// when the link was sent for a subscription.
+type AlertAction struct {
+	Action      string `json:"a"`
+	ThresholdID int64  `json:"t"`
+	RuleID      int64  `json:"r,omitempty"`
+	Email       string `json:"e"`
+	Symbol      string `json:"s"`
+	Locale      string `json:"l,omitempty"`
+	ExpiresAt   int64  `json:"x"`
+}
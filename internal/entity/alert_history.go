@@ -0,0 +1,23 @@
+package entity
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// AlertHistory is a fired alert as recorded by the scans. Portfolio alerts
+// have no subscription and are recorded with ThresholdID 0. Message holds
+// the alert as streamed to dashboards, and is empty for older entries.
+type AlertHistory struct {
+	ID          int64     `json:"id"`
+	ThresholdID int64     `json:"threshold_id"`
+	Email       string    `json:"email"`
+	Symbol      string    `json:"symbol"`
+	Period      string    `json:"period"`
+	Direction   string    `json:"direction"`
+	Price       float64   `json:"price"`
+	Subject     string    `json:"subject"`
+	CreatedAt   time.Time `json:"created_at"`
+
+	Message json.RawMessage `json:"message,omitempty"`
+}
@@ -0,0 +1,51 @@
+package entity
+
+import "time"
+
+const (
+	RuleKindWindow      = "window"
+	RuleKindTargetPrice = "target_price"
+)
+
+// AlertRule is one condition of a subscription. Window rules fire when the
+// percent change over Period reaches Value in Direction; target price rules
+// fire when the price crosses Value in Direction.
+type AlertRule struct {
+	ID        int64   `json:"id,omitempty"`
+	Kind      string  `json:"kind"`
+	Period    string  `json:"period,omitempty"`
+	Direction string  `json:"direction"`
+	Value     float64 `json:"value"`
+	Enabled   bool    `json:"enabled"`
+}
+
+// Source tells where the percent change of a window rule comes from: the
+// CoinMarketCap quote when precomputed there, otherwise daily history for
+// whole days and hourly history for anything finer.
+func (r AlertRule) Source() string {
+	duration, err := ParseWindow(r.Period)
+	if err != nil {
+		return ""
+	}
+	if _, ok := quoteWindows[duration]; ok {
+		return WindowSourceQuote
+	}
+	if duration%(24*time.Hour) == 0 {
+		return WindowSourceDailyHistory
+	}
+	return WindowSourceHourlyHistory
+}
+
+// QuotePercentChange returns the precomputed percent change for windows
+// CoinMarketCap provides.
+func (r AlertRule) QuotePercentChange(data *CryptoCurrency) (float64, bool) {
+	duration, err := ParseWindow(r.Period)
+	if err != nil {
+		return 0, false
+	}
+	percentChange, ok := quoteWindows[duration]
+	if !ok {
+		return 0, false
+	}
+	return percentChange(data), true
+}
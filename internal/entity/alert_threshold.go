@@ -1,48 +1,160 @@
 package entity
 
+import "time"
+
+const (
+	AlertStateActive    = "active"
+	AlertStatePaused    = "paused"
+	AlertStateTriggered = "triggered"
+	AlertStateExpired   = "expired"
+
+	// Waiting for the owner to confirm the email address
+	AlertStatePending = "pending"
+)
+
+// AlertStateTransitions lists, for each state users may set, the states an
+// alert may be moved from. Triggered and expired are terminal and pending
+// only ends through the confirmation link.
+var AlertStateTransitions = map[string][]string{
+	AlertStateActive: {AlertStateActive, AlertStatePaused},
+	AlertStatePaused: {AlertStateActive, AlertStatePaused},
+}
+
 type AlertThreshold struct {
+	ID           int64  `json:"id"`
 	Email        string `json:"email"`
 	CryptoSymbol string `json:"crypto_symbol"`
 
-	// 1h thresholds
-	ThresholdUp1hPercent   *float64 `json:"threshold_up_1h_percent"`
-	ThresholdUp1hEnabled   bool     `json:"threshold_up_1h_enabled"`
-	ThresholdDown1hPercent *float64 `json:"threshold_down_1h_percent"`
-	ThresholdDown1hEnabled bool     `json:"threshold_down_1h_enabled"`
-
-	// 24h thresholds
-	ThresholdUp24hPercent   *float64 `json:"threshold_up_24h_percent"`
-	ThresholdUp24hEnabled   bool     `json:"threshold_up_24h_enabled"`
-	ThresholdDown24hPercent *float64 `json:"threshold_down_24h_percent"`
-	ThresholdDown24hEnabled bool     `json:"threshold_down_24h_enabled"`
-
-	// 7d thresholds
-	ThresholdUp7dPercent   *float64 `json:"threshold_up_7d_percent"`
-	ThresholdUp7dEnabled   bool     `json:"threshold_up_7d_enabled"`
-	ThresholdDown7dPercent *float64 `json:"threshold_down_7d_percent"`
-	ThresholdDown7dEnabled bool     `json:"threshold_down_7d_enabled"`
-
-	// 30d thresholds
-	ThresholdUp30dPercent   *float64 `json:"threshold_up_30d_percent"`
-	ThresholdUp30dEnabled   bool     `json:"threshold_up_30d_enabled"`
-	ThresholdDown30dPercent *float64 `json:"threshold_down_30d_percent"`
-	ThresholdDown30dEnabled bool     `json:"threshold_down_30d_enabled"`
-
-	// 60d thresholds
-	ThresholdUp60dPercent   *float64 `json:"threshold_up_60d_percent"`
-	ThresholdUp60dEnabled   bool     `json:"threshold_up_60d_enabled"`
-	ThresholdDown60dPercent *float64 `json:"threshold_down_60d_percent"`
-	ThresholdDown60dEnabled bool     `json:"threshold_down_60d_enabled"`
-
-	// 90d thresholds
-	ThresholdUp90dPercent   *float64 `json:"threshold_up_90d_percent"`
-	ThresholdUp90dEnabled   bool     `json:"threshold_up_90d_enabled"`
-	ThresholdDown90dPercent *float64 `json:"threshold_down_90d_percent"`
-	ThresholdDown90dEnabled bool     `json:"threshold_down_90d_enabled"`
-
-	// Target price thresholds
-	TargetPriceUp      *float64 `json:"target_price_up"`
-	TargetPriceUpEnabled   bool     `json:"target_price_up_enabled"`
-	TargetPriceDown    *float64 `json:"target_price_down"`
-	TargetPriceDownEnabled bool     `json:"target_price_down_enabled"`
+	// Quote asset for ratio alerts (e.g. BTC for ETH/BTC); empty means USD
+	QuoteSymbol string `json:"quote_symbol"`
+
+	// Window and target price rules
+	Rules []AlertRule `json:"rules"`
+
+	// Composite rule combining several conditions with and/or
+	CompositeRule        *Condition `json:"composite_rule"`
+	CompositeRuleEnabled bool       `json:"composite_rule_enabled"`
+
+	// Custom expression, e.g. "pct_change_24h < -5 && volume_24h > 2 * avg_volume_90d"
+	CustomExpression        *string `json:"custom_expression"`
+	CustomExpressionEnabled bool    `json:"custom_expression_enabled"`
+
+	// Stablecoin depeg monitoring
+	DepegEnabled          bool     `json:"depeg_enabled"`
+	DepegPegPrice         *float64 `json:"depeg_peg_price"`
+	DepegBandBps          *float64 `json:"depeg_band_bps"`
+	DepegSustainedScans   int      `json:"depeg_sustained_scans"`
+	DepegConsecutiveScans int      `json:"depeg_consecutive_scans"`
+
+	// Historical range alerts (new high/low and drawdown from high)
+	RangeLookbackDays int      `json:"range_lookback_days"`
+	NewHighEnabled    bool     `json:"new_high_enabled"`
+	NewLowEnabled     bool     `json:"new_low_enabled"`
+	DrawdownPercent   *float64 `json:"drawdown_percent"`
+	DrawdownEnabled   bool     `json:"drawdown_enabled"`
+
+	// Volatility regime alerts (short-window vs long-window realized volatility)
+	VolatilityEnabled      bool     `json:"volatility_enabled"`
+	VolatilityShortWindow  int      `json:"volatility_short_window"`
+	VolatilityLongWindow   int      `json:"volatility_long_window"`
+	VolatilityMultiple     *float64 `json:"volatility_multiple"`
+	VolatilityRegimeActive bool     `json:"volatility_regime_active"`
+
+	// Price crossing state, updated on every scan
+	LastObservedPrice  *float64   `json:"last_observed_price,omitempty"`
+	LastCrossDirection string     `json:"last_cross_direction,omitempty"`
+	LastCrossedAt      *time.Time `json:"last_crossed_at,omitempty"`
+
+	// Critical alerts are delivered even during the user's quiet hours
+	Critical bool `json:"critical"`
+
+	// Language of the emails, e.g. pt-BR, en or es
+	Locale string `json:"locale"`
+
+	// Scheduled summary instead of immediate emails; empty means immediate
+	DigestFrequency string     `json:"digest_frequency"`
+	LastDigestAt    *time.Time `json:"last_digest_at,omitempty"`
+
+	// Lifecycle
+	State        string     `json:"state"`
+	OneShot      bool       `json:"one_shot"`
+	MaxTriggers  *int       `json:"max_triggers"`
+	TriggerCount int        `json:"trigger_count"`
+	StartsAt     *time.Time `json:"starts_at"`
+	ExpiresAt    *time.Time `json:"expires_at"`
+	SnoozedUntil *time.Time `json:"snoozed_until,omitempty"`
+	// State the user asked for, restored when a pending threshold is confirmed
+	RequestedState string `json:"-"`
+}
+
+const DefaultPegPrice = 1.0
+
+const DefaultRangeLookbackDays = 90
+
+var RangeLookbackDays = map[int]bool{30: true, 90: true, 365: true}
+
+const (
+	DefaultVolatilityShortWindow = 7
+	DefaultVolatilityLongWindow  = 90
+	MaxVolatilityLongWindow      = 365
+)
+
+// RequiredHistoryDays returns how many days of daily history the enabled
+// alerts need, or 0 when none of them uses history.
+func (t *AlertThreshold) RequiredHistoryDays() int {
+	days := 0
+	for _, rule := range t.Rules {
+		if !rule.Enabled || rule.Kind != RuleKindWindow || rule.Source() != WindowSourceDailyHistory {
+			continue
+		}
+		if duration, err := ParseWindow(rule.Period); err == nil && windowDays(duration)+1 > days {
+			days = windowDays(duration) + 1
+		}
+	}
+	if t.HasRangeAlerts() && t.RangeLookbackDays > days {
+		days = t.RangeLookbackDays
+	}
+	if t.VolatilityEnabled && t.VolatilityLongWindow+1 > days {
+		days = t.VolatilityLongWindow + 1
+	}
+	return days
+}
+
+func (t *AlertThreshold) HasRangeAlerts() bool {
+	return t.NewHighEnabled || t.NewLowEnabled || t.DrawdownEnabled
+}
+
+// RequiredHourlyHistoryDays returns how many days of hourly history the
+// enabled sub-day windows need, or 0 when none does.
+func (t *AlertThreshold) RequiredHourlyHistoryDays() int {
+	days := 0
+	for _, rule := range t.Rules {
+		if !rule.Enabled || rule.Kind != RuleKindWindow || rule.Source() != WindowSourceHourlyHistory {
+			continue
+		}
+		if duration, err := ParseWindow(rule.Period); err == nil && windowDays(duration)+1 > days {
+			days = windowDays(duration) + 1
+		}
+	}
+	return days
+}
+
+// TracksPriceCrossings reports whether any enabled alert compares the current
+// price with the one observed on the previous scan.
+func (t *AlertThreshold) TracksPriceCrossings() bool {
+	for _, rule := range t.Rules {
+		if rule.Enabled && rule.Kind == RuleKindTargetPrice {
+			return true
+		}
+	}
+	return t.HasRangeAlerts()
+}
+
+// IsExhausted reports whether the threshold already fired as many times as
+// its lifecycle allows.
+func (t *AlertThreshold) IsExhausted() bool {
+	if t.OneShot && t.TriggerCount > 0 {
+		return true
+	}
+	return t.MaxTriggers != nil && t.TriggerCount >= *t.MaxTriggers
 }
@@ -0,0 +1,134 @@
+package entity
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// legacyPeriods are the fixed windows of the original API, which sent one
+// threshold_<direction>_<period>_percent/_enabled pair per window.
+var legacyPeriods = []string{"1h", "24h", "7d", "30d", "60d", "90d"}
+
+// legacyRuleFields is the pre-rules shape of window and target price
+// thresholds. Clients may still send it, and responses keep carrying it.
+type legacyRuleFields struct {
+	WindowThresholds       []WindowThreshold `json:"window_thresholds"`
+	TargetPriceUp          *float64          `json:"target_price_up"`
+	TargetPriceUpEnabled   bool              `json:"target_price_up_enabled"`
+	TargetPriceDown        *float64          `json:"target_price_down"`
+	TargetPriceDownEnabled bool              `json:"target_price_down_enabled"`
+}
+
+type alertThresholdJSON AlertThreshold
+
+func (t *AlertThreshold) UnmarshalJSON(data []byte) error {
+	var decoded alertThresholdJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+
+	var legacy legacyRuleFields
+	if err := json.Unmarshal(data, &legacy); err != nil {
+		return err
+	}
+
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+
+	*t = AlertThreshold(decoded)
+
+	// Responses carry both shapes, so a payload echoed back with rules must not
+	// have its rules duplicated from the legacy fields.
+	if _, ok := fields["rules"]; ok {
+		return nil
+	}
+
+	for _, period := range legacyPeriods {
+		for _, direction := range []string{DirectionUp, DirectionDown} {
+			var percent *float64
+			var enabled bool
+			if err := decodeLegacyField(fields, fmt.Sprintf("threshold_%s_%s_percent", direction, period), &percent); err != nil {
+				return err
+			}
+			if err := decodeLegacyField(fields, fmt.Sprintf("threshold_%s_%s_enabled", direction, period), &enabled); err != nil {
+				return err
+			}
+			if percent == nil {
+				continue
+			}
+			t.Rules = append(t.Rules, AlertRule{
+				Kind:      RuleKindWindow,
+				Period:    period,
+				Direction: direction,
+				Value:     *percent,
+				Enabled:   enabled,
+			})
+		}
+	}
+
+	for _, window := range legacy.WindowThresholds {
+		t.Rules = append(t.Rules, window.Rule())
+	}
+
+	if legacy.TargetPriceUp != nil || legacy.TargetPriceUpEnabled {
+		t.Rules = append(t.Rules, legacyTargetRule(DirectionUp, legacy.TargetPriceUp, legacy.TargetPriceUpEnabled))
+	}
+	if legacy.TargetPriceDown != nil || legacy.TargetPriceDownEnabled {
+		t.Rules = append(t.Rules, legacyTargetRule(DirectionDown, legacy.TargetPriceDown, legacy.TargetPriceDownEnabled))
+	}
+
+	return nil
+}
+
+func (t AlertThreshold) MarshalJSON() ([]byte, error) {
+	legacy := legacyRuleFields{WindowThresholds: []WindowThreshold{}}
+
+	for _, rule := range t.Rules {
+		switch rule.Kind {
+		case RuleKindWindow:
+			legacy.WindowThresholds = append(legacy.WindowThresholds, WindowThreshold{
+				Window:    rule.Period,
+				Direction: rule.Direction,
+				Percent:   rule.Value,
+				Enabled:   rule.Enabled,
+			})
+		case RuleKindTargetPrice:
+			value := rule.Value
+			if rule.Direction == DirectionUp {
+				legacy.TargetPriceUp = &value
+				legacy.TargetPriceUpEnabled = rule.Enabled
+			} else {
+				legacy.TargetPriceDown = &value
+				legacy.TargetPriceDownEnabled = rule.Enabled
+			}
+		}
+	}
+
+	return json.Marshal(struct {
+		alertThresholdJSON
+		legacyRuleFields
+	}{alertThresholdJSON(t), legacy})
+}
+
+func decodeLegacyField(fields map[string]json.RawMessage, name string, target interface{}) error {
+	raw, ok := fields[name]
+	if !ok {
+		return nil
+	}
+	if err := json.Unmarshal(raw, target); err != nil {
+		return fmt.Errorf("invalid %s: %w", name, err)
+	}
+	return nil
+}
+
+// legacyTargetRule keeps an enabled target without a price as a zero-valued
+// rule, so validation still rejects it instead of silently dropping it.
+func legacyTargetRule(direction string, price *float64, enabled bool) AlertRule {
+	rule := AlertRule{Kind: RuleKindTargetPrice, Direction: direction, Enabled: enabled}
+	if price != nil {
+		rule.Value = *price
+	}
+	return rule
+}
@@ -18,6 +18,7 @@ type VolumePoint struct {
 
 type HistoricalPriceData struct {
 	Symbol    string              `json:"symbol"`
+	Quote     string              `json:"quote,omitempty"`
 	Prices    []PriceHistoryPoint `json:"prices"`
 	Volumes   []VolumePoint       `json:"volumes"`
 	MinPrice  float64             `json:"min_price"`
@@ -28,3 +29,71 @@ type HistoricalPriceData struct {
 	AvgVolume float64             `json:"avg_volume"`
 	DaysCount int                 `json:"days_count"`
 }
+
+const millisecondsPerDay = 24 * 60 * 60 * 1000
+
+// LastDays returns a copy restricted to the trailing window of the given
+// number of days, measured back from the most recent price point.
+func (h *HistoricalPriceData) LastDays(days int) *HistoricalPriceData {
+	if h == nil || len(h.Prices) == 0 {
+		return h
+	}
+
+	cutoff := h.Prices[len(h.Prices)-1].Timestamp - int64(days)*millisecondsPerDay
+
+	window := &HistoricalPriceData{
+		Symbol: h.Symbol,
+		Quote:  h.Quote,
+	}
+	for _, point := range h.Prices {
+		if point.Timestamp >= cutoff {
+			window.Prices = append(window.Prices, point)
+		}
+	}
+	for _, point := range h.Volumes {
+		if point.Timestamp >= cutoff {
+			window.Volumes = append(window.Volumes, point)
+		}
+	}
+
+	window.RefreshStats()
+
+	return window
+}
+
+// RefreshStats recomputes the min/max/average summaries from Prices and
+// Volumes.
+func (h *HistoricalPriceData) RefreshStats() {
+	h.MinPrice, h.MaxPrice, h.AvgPrice = 0, 0, 0
+	h.MinVolume, h.MaxVolume, h.AvgVolume = 0, 0, 0
+
+	var priceSum float64
+	for i, point := range h.Prices {
+		priceSum += point.Price
+		if i == 0 || point.Price < h.MinPrice {
+			h.MinPrice = point.Price
+		}
+		if i == 0 || point.Price > h.MaxPrice {
+			h.MaxPrice = point.Price
+		}
+	}
+	if len(h.Prices) > 0 {
+		h.AvgPrice = priceSum / float64(len(h.Prices))
+	}
+
+	var volumeSum float64
+	for i, point := range h.Volumes {
+		volumeSum += point.Volume
+		if i == 0 || point.Volume < h.MinVolume {
+			h.MinVolume = point.Volume
+		}
+		if i == 0 || point.Volume > h.MaxVolume {
+			h.MaxVolume = point.Volume
+		}
+	}
+	if len(h.Volumes) > 0 {
+		h.AvgVolume = volumeSum / float64(len(h.Volumes))
+	}
+
+	h.DaysCount = len(h.Prices)
+}
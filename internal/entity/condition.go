@@ -0,0 +1,153 @@
+package entity
+
+import "fmt"
+
+const (
+	ConditionOperatorAnd = "and"
+	ConditionOperatorOr  = "or"
+)
+
+const maxConditionDepth = 5
+
+const (
+	MetricPrice              = "price"
+	MetricVolume24h          = "volume_24h"
+	MetricVolumeChange24h    = "volume_change_24h"
+	MetricMarketCap          = "market_cap"
+	MetricMarketCapDominance = "market_cap_dominance"
+	MetricPctChange1h        = "pct_change_1h"
+	MetricPctChange24h       = "pct_change_24h"
+	MetricPctChange7d        = "pct_change_7d"
+	MetricPctChange30d       = "pct_change_30d"
+	MetricPctChange60d       = "pct_change_60d"
+	MetricPctChange90d       = "pct_change_90d"
+	MetricMinPrice90d        = "min_price_90d"
+	MetricMaxPrice90d        = "max_price_90d"
+	MetricAvgPrice90d        = "avg_price_90d"
+	MetricMinVolume90d       = "min_volume_90d"
+	MetricMaxVolume90d       = "max_volume_90d"
+	MetricAvgVolume90d       = "avg_volume_90d"
+	MetricFearGreedIndex     = "fear_greed"
+)
+
+// ConditionMetrics lists the variables available to composite rules and
+// custom expressions.
+var ConditionMetrics = map[string]bool{
+	MetricPrice:              true,
+	MetricVolume24h:          true,
+	MetricVolumeChange24h:    true,
+	MetricMarketCap:          true,
+	MetricMarketCapDominance: true,
+	MetricPctChange1h:        true,
+	MetricPctChange24h:       true,
+	MetricPctChange7d:        true,
+	MetricPctChange30d:       true,
+	MetricPctChange60d:       true,
+	MetricPctChange90d:       true,
+	MetricMinPrice90d:        true,
+	MetricMaxPrice90d:        true,
+	MetricAvgPrice90d:        true,
+	MetricMinVolume90d:       true,
+	MetricMaxVolume90d:       true,
+	MetricAvgVolume90d:       true,
+	MetricFearGreedIndex:     true,
+}
+
+var conditionComparators = map[string]func(actual, expected float64) bool{
+	">":  func(actual, expected float64) bool { return actual > expected },
+	">=": func(actual, expected float64) bool { return actual >= expected },
+	"<":  func(actual, expected float64) bool { return actual < expected },
+	"<=": func(actual, expected float64) bool { return actual <= expected },
+}
+
+// Condition is a node of a composite rule. Group nodes set Operator and
+// Conditions; leaf nodes compare Metric against Value using Comparator.
+type Condition struct {
+	Operator   string      `json:"operator,omitempty"`
+	Conditions []Condition `json:"conditions,omitempty"`
+
+	Metric     string  `json:"metric,omitempty"`
+	Comparator string  `json:"comparator,omitempty"`
+	Value      float64 `json:"value,omitempty"`
+}
+
+type ConditionResult struct {
+	Metric     string
+	Comparator string
+	Value      float64
+	Actual     float64
+	Available  bool
+	Matched    bool
+}
+
+func (c *Condition) IsGroup() bool {
+	return c.Operator != ""
+}
+
+func (c *Condition) Validate() error {
+	return c.validate(1)
+}
+
+func (c *Condition) validate(depth int) error {
+	if depth > maxConditionDepth {
+		return fmt.Errorf("composite rule is nested deeper than %d levels", maxConditionDepth)
+	}
+
+	if c.IsGroup() {
+		if c.Operator != ConditionOperatorAnd && c.Operator != ConditionOperatorOr {
+			return fmt.Errorf("composite rule operator must be %s or %s", ConditionOperatorAnd, ConditionOperatorOr)
+		}
+		if len(c.Conditions) == 0 {
+			return fmt.Errorf("composite rule group %s has no conditions", c.Operator)
+		}
+		for i := range c.Conditions {
+			if err := c.Conditions[i].validate(depth + 1); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if !ConditionMetrics[c.Metric] {
+		return fmt.Errorf("composite rule metric %q is not supported", c.Metric)
+	}
+	if _, ok := conditionComparators[c.Comparator]; !ok {
+		return fmt.Errorf("composite rule comparator %q is not supported", c.Comparator)
+	}
+
+	return nil
+}
+
+// Evaluate resolves the tree against the given metric values. Every leaf is
+// evaluated, even after the outcome is known, so the results can explain the
+// decision. A leaf whose metric is missing never matches.
+func (c *Condition) Evaluate(values map[string]float64) (bool, []ConditionResult) {
+	if !c.IsGroup() {
+		actual, available := values[c.Metric]
+		matched := available && conditionComparators[c.Comparator](actual, c.Value)
+		return matched, []ConditionResult{{
+			Metric:     c.Metric,
+			Comparator: c.Comparator,
+			Value:      c.Value,
+			Actual:     actual,
+			Available:  available,
+			Matched:    matched,
+		}}
+	}
+
+	var results []ConditionResult
+	matched := c.Operator == ConditionOperatorAnd
+
+	for i := range c.Conditions {
+		childMatched, childResults := c.Conditions[i].Evaluate(values)
+		results = append(results, childResults...)
+
+		if c.Operator == ConditionOperatorAnd {
+			matched = matched && childMatched
+		} else {
+			matched = matched || childMatched
+		}
+	}
+
+	return matched, results
+}
@@ -0,0 +1,34 @@
+package entity
+
+import "time"
+
+const (
+	DigestFrequencyNone   = ""
+	DigestFrequencyDaily  = "daily"
+	DigestFrequencyWeekly = "weekly"
+)
+
+var DigestFrequencies = map[string]bool{
+	DigestFrequencyNone:   true,
+	DigestFrequencyDaily:  true,
+	DigestFrequencyWeekly: true,
+}
+
+// DigestSlot returns the most recent scheduled send time at or before now,
+// in the subscriber's location: every day at hour for daily digests, and
+// Mondays at hour for weekly ones. A digest is due when it was last sent
+// before its current slot.
+func DigestSlot(frequency string, hour int, now time.Time, location *time.Location) time.Time {
+	now = now.In(location)
+	slot := time.Date(now.Year(), now.Month(), now.Day(), hour, 0, 0, 0, location)
+	if slot.After(now) {
+		slot = slot.AddDate(0, 0, -1)
+	}
+
+	if frequency == DigestFrequencyWeekly {
+		daysSinceMonday := (int(slot.Weekday()) + 6) % 7
+		slot = slot.AddDate(0, 0, -daysSinceMonday)
+	}
+
+	return slot
+}
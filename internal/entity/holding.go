@@ -0,0 +1,110 @@
+package entity
+
+// Holding is a position of one user in one asset. P&L thresholds fire when
+// the unrealized gain or loss crosses them, so they are checked against the
+// price observed on the previous scan like target prices.
+type Holding struct {
+	ID                int64   `json:"id"`
+	Email             string  `json:"email"`
+	CryptoSymbol      string  `json:"crypto_symbol"`
+	Quantity          float64 `json:"quantity"`
+	AverageEntryPrice float64 `json:"average_entry_price"`
+
+	// Unrealized P&L thresholds, in percent of cost basis and in USD
+	PnLPercentUp   *float64 `json:"pnl_percent_up"`
+	PnLPercentDown *float64 `json:"pnl_percent_down"`
+	PnLAmountUp    *float64 `json:"pnl_amount_up"`
+	PnLAmountDown  *float64 `json:"pnl_amount_down"`
+
+	LastObservedPrice *float64 `json:"last_observed_price,omitempty"`
+
+	// Language of the P&L emails, e.g. pt-BR, en or es
+	Locale string `json:"locale"`
+}
+
+// PortfolioAlert holds the total portfolio value thresholds of one user.
+type PortfolioAlert struct {
+	Email             string   `json:"email"`
+	ValueAbove        *float64 `json:"value_above"`
+	ValueBelow        *float64 `json:"value_below"`
+	LastObservedValue *float64 `json:"last_observed_value,omitempty"`
+
+	// Language of the portfolio emails, e.g. pt-BR, en or es
+	Locale string `json:"locale"`
+}
+
+func (h *Holding) CostBasis() float64 {
+	return h.Quantity * h.AverageEntryPrice
+}
+
+func (h *Holding) HasPnLAlerts() bool {
+	return h.PnLPercentUp != nil || h.PnLPercentDown != nil || h.PnLAmountUp != nil || h.PnLAmountDown != nil
+}
+
+// PriceForPnLPercent returns the price at which the unrealized P&L equals
+// percent of the cost basis.
+func (h *Holding) PriceForPnLPercent(percent float64) float64 {
+	return h.AverageEntryPrice * (1 + percent/100)
+}
+
+// PriceForPnLAmount returns the price at which the unrealized P&L equals
+// amount in USD.
+func (h *Holding) PriceForPnLAmount(amount float64) float64 {
+	return h.AverageEntryPrice + amount/h.Quantity
+}
+
+// Position is a holding valued at the current market price.
+type Position struct {
+	Holding
+	Price      float64 `json:"price"`
+	Value      float64 `json:"value"`
+	PnLAmount  float64 `json:"pnl_amount"`
+	PnLPercent float64 `json:"pnl_percent"`
+}
+
+func NewPosition(holding *Holding, price float64) Position {
+	position := Position{
+		Holding:   *holding,
+		Price:     price,
+		Value:     holding.Quantity * price,
+		PnLAmount: holding.Quantity * (price - holding.AverageEntryPrice),
+	}
+	if costBasis := holding.CostBasis(); costBasis > 0 {
+		position.PnLPercent = position.PnLAmount / costBasis * 100
+	}
+	return position
+}
+
+type Portfolio struct {
+	Email      string     `json:"email"`
+	Positions  []Position `json:"positions"`
+	TotalValue float64    `json:"total_value"`
+	TotalCost  float64    `json:"total_cost"`
+	PnLAmount  float64    `json:"pnl_amount"`
+	PnLPercent float64    `json:"pnl_percent"`
+}
+
+// NewPortfolio values the holdings at the given prices. Holdings without a
+// price are left out of the totals.
+func NewPortfolio(email string, holdings []*Holding, prices map[string]*CryptoCurrency) *Portfolio {
+	portfolio := &Portfolio{Email: email, Positions: []Position{}}
+
+	for _, holding := range holdings {
+		data, ok := prices[holding.CryptoSymbol]
+		if !ok {
+			continue
+		}
+
+		position := NewPosition(holding, data.Price)
+		portfolio.Positions = append(portfolio.Positions, position)
+		portfolio.TotalValue += position.Value
+		portfolio.TotalCost += holding.CostBasis()
+	}
+
+	portfolio.PnLAmount = portfolio.TotalValue - portfolio.TotalCost
+	if portfolio.TotalCost > 0 {
+		portfolio.PnLPercent = portfolio.PnLAmount / portfolio.TotalCost * 100
+	}
+
+	return portfolio
+}
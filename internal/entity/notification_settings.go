@@ -0,0 +1,81 @@
+package entity
+
+import (
+	"fmt"
+	"time"
+)
+
+// NotificationSettings are the delivery preferences of one user. During
+// quiet hours, in the user's time zone, non-critical alerts are queued and
+// delivered once the window ends, one by one or as a single summary.
+type NotificationSettings struct {
+	Email           string `json:"email"`
+	TimeZone        string `json:"time_zone"`
+	QuietHoursStart string `json:"quiet_hours_start"`
+	QuietHoursEnd   string `json:"quiet_hours_end"`
+	SummarizeQueued bool   `json:"summarize_queued"`
+}
+
+// QueuedAlert is an email held back by quiet hours.
+type QueuedAlert struct {
+	ID      int64  `json:"id"`
+	Email   string `json:"email"`
+	Subject string `json:"subject"`
+	Body    string `json:"body"`
+	// Plain text alternative of Body, empty for alerts queued before it was kept
+	TextBody string `json:"text_body"`
+	// Language the alerts were rendered in, used for the summary email
+	Locale    string    `json:"locale"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// ParseClock parses a wall-clock time written as "HH:MM" and returns the
+// minutes since midnight.
+func ParseClock(clock string) (int, error) {
+	parsed, err := time.Parse("15:04", clock)
+	if err != nil {
+		return 0, fmt.Errorf("invalid time %q: use HH:MM", clock)
+	}
+	return parsed.Hour()*60 + parsed.Minute(), nil
+}
+
+// Location is the user's time zone, UTC when unset or unknown.
+func (s *NotificationSettings) Location() *time.Location {
+	if s == nil || s.TimeZone == "" {
+		return time.UTC
+	}
+	location, err := time.LoadLocation(s.TimeZone)
+	if err != nil {
+		return time.UTC
+	}
+	return location
+}
+
+func (s *NotificationSettings) HasQuietHours() bool {
+	return s.QuietHoursStart != "" && s.QuietHoursEnd != "" && s.QuietHoursStart != s.QuietHoursEnd
+}
+
+// InQuietHours reports whether now falls inside the quiet window in the
+// user's time zone. Windows may wrap around midnight, e.g. 22:00 to 07:00.
+func (s *NotificationSettings) InQuietHours(now time.Time) bool {
+	if s == nil || !s.HasQuietHours() {
+		return false
+	}
+
+	start, err := ParseClock(s.QuietHoursStart)
+	if err != nil {
+		return false
+	}
+	end, err := ParseClock(s.QuietHoursEnd)
+	if err != nil {
+		return false
+	}
+
+	local := now.In(s.Location())
+	minute := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return minute >= start && minute < end
+	}
+	return minute >= start || minute < end
+}
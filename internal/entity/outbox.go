@@ -0,0 +1,39 @@
+package entity
+
+import "time"
+
+// A pending message is claimed as sending by one delivery run at a time;
+// the claim lapses at LockedUntil should that run die mid-send.
+const (
+	OutboxStatusPending = "pending"
+	OutboxStatusSending = "sending"
+	OutboxStatusSent    = "sent"
+	OutboxStatusDead    = "dead"
+)
+
+// OutboxMessage is an email waiting to be delivered, retried with backoff
+// until it is sent or dead-lettered after too many failures. Failures is
+// only loaded for dead letters and keeps the attempts made before each
+// redrive.
+type OutboxMessage struct {
+	ID            int64      `json:"id"`
+	Email         string     `json:"email"`
+	Subject       string     `json:"subject"`
+	Body          string     `json:"body,omitempty"`
+	TextBody      string     `json:"text_body,omitempty"`
+	Status        string     `json:"status"`
+	Attempts      int        `json:"attempts"`
+	NextAttemptAt time.Time  `json:"next_attempt_at"`
+	LastError     string     `json:"last_error"`
+	CreatedAt     time.Time  `json:"created_at"`
+	SentAt        *time.Time `json:"sent_at,omitempty"`
+	RedriveCount  int        `json:"redrive_count"`
+
+	Failures []OutboxFailure `json:"failures,omitempty"`
+}
+
+type OutboxFailure struct {
+	Attempt  int       `json:"attempt"`
+	Error    string    `json:"error"`
+	FailedAt time.Time `json:"failed_at"`
+}
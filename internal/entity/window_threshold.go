@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+)
+
+const (
+	WindowSourceQuote         = "quote"
+	WindowSourceDailyHistory  = "daily_history"
+	WindowSourceHourlyHistory = "hourly_history"
+)
+
+const MaxWindowDuration = 365 * 24 * time.Hour
+
+// MaxHourlyWindowDuration bounds windows that are not whole days, since
+// CoinGecko only serves hourly granularity for up to 90 days of history.
+const MaxHourlyWindowDuration = 89 * 24 * time.Hour
+
+// WindowThreshold is the legacy JSON shape of a window rule, still accepted
+// and returned by the API. Windows are written as a number of hours or days,
+// e.g. "4h", "24h", "3d" or "180d".
+type WindowThreshold struct {
+	Window    string  `json:"window"`
+	Direction string  `json:"direction"`
+	Percent   float64 `json:"percent"`
+	Enabled   bool    `json:"enabled"`
+}
+
+// quoteWindows are the windows CoinMarketCap already precomputes.
+var quoteWindows = map[time.Duration]func(data *CryptoCurrency) float64{
+	time.Hour:           func(data *CryptoCurrency) float64 { return data.PercentChange1h },
+	24 * time.Hour:      func(data *CryptoCurrency) float64 { return data.PercentChange24h },
+	7 * 24 * time.Hour:  func(data *CryptoCurrency) float64 { return data.PercentChange7d },
+	30 * 24 * time.Hour: func(data *CryptoCurrency) float64 { return data.PercentChange30d },
+	60 * 24 * time.Hour: func(data *CryptoCurrency) float64 { return data.PercentChange60d },
+	90 * 24 * time.Hour: func(data *CryptoCurrency) float64 { return data.PercentChange90d },
+}
+
+func ParseWindow(window string) (time.Duration, error) {
+	window = strings.TrimSpace(strings.ToLower(window))
+	if len(window) < 2 {
+		return 0, fmt.Errorf("invalid window %q", window)
+	}
+
+	unit := time.Hour
+	switch window[len(window)-1] {
+	case 'h':
+	case 'd':
+		unit = 24 * time.Hour
+	default:
+		return 0, fmt.Errorf("invalid window %q: use hours (h) or days (d)", window)
+	}
+
+	value, err := strconv.Atoi(window[:len(window)-1])
+	if err != nil || value <= 0 {
+		return 0, fmt.Errorf("invalid window %q", window)
+	}
+
+	duration := time.Duration(value) * unit
+	if duration > MaxWindowDuration {
+		return 0, fmt.Errorf("window %q is longer than 365 days", window)
+	}
+
+	return duration, nil
+}
+
+// Rule converts the legacy window threshold into an AlertRule.
+func (w WindowThreshold) Rule() AlertRule {
+	return AlertRule{
+		Kind:      RuleKindWindow,
+		Period:    w.Window,
+		Direction: w.Direction,
+		Value:     w.Percent,
+		Enabled:   w.Enabled,
+	}
+}
+
+func windowDays(duration time.Duration) int {
+	return int((duration + 24*time.Hour - 1) / (24 * time.Hour))
+}
@@ -1,9 +1,18 @@
 package handler
 
 import (
+	"crypto/subtle"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"html"
+	"io"
 	"log"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
 
 	"crypto-alerts/internal/config"
 	"crypto-alerts/internal/entity"
@@ -11,36 +20,121 @@ import (
 	apiRepo "crypto-alerts/internal/repository/api"
 	"crypto-alerts/internal/repository/db"
 	notifierRepo "crypto-alerts/internal/repository/notifier"
+	"crypto-alerts/internal/scheduler"
 	"crypto-alerts/internal/usecase"
 )
 
+const (
+	// digestCheckInterval is how often the scheduler looks for due digests.
+	digestCheckInterval = 15 * time.Minute
+	// queueCheckInterval is how often alerts held by quiet hours are retried.
+	queueCheckInterval = 5 * time.Minute
+	// pendingCheckInterval is how often unconfirmed subscriptions are expired.
+	pendingCheckInterval = time.Hour
+	// outboxCheckInterval is how often the outbox is retried between scans.
+	outboxCheckInterval = time.Minute
+	// streamHeartbeatInterval keeps idle alert streams from being closed by
+	// proxies.
+	streamHeartbeatInterval = 15 * time.Second
+)
+
 type CheckAlertsResponse struct {
 	AlertsTriggered int `json:"alerts_triggered"`
 }
 
+type RedriveRequest struct {
+	IDs []int64 `json:"ids"`
+}
+
+type UpdateStateRequest struct {
+	ID    int64  `json:"id"`
+	Email string `json:"email"`
+	State string `json:"state"`
+}
+
 type API struct {
 	config                  *config.Config
 	createAlertUseCase      usecase.CreateAlertUseCase
 	executeAlertScanUseCase usecase.ExecuteAlertScanUseCase
-	db                      *pkg.DB
+	listAlertsUseCase       usecase.ListAlertsUseCase
+	updateAlertStateUseCase usecase.UpdateAlertStateUseCase
+
+	saveHoldingUseCase          usecase.SaveHoldingUseCase
+	savePortfolioAlertUseCase   usecase.SavePortfolioAlertUseCase
+	getPortfolioUseCase         usecase.GetPortfolioUseCase
+	executePortfolioScanUseCase usecase.ExecutePortfolioScanUseCase
+	sendDigestsUseCase          usecase.SendDigestsUseCase
+
+	saveNotificationSettingsUseCase usecase.SaveNotificationSettingsUseCase
+	deliverQueuedAlertsUseCase      usecase.DeliverQueuedAlertsUseCase
+
+	applyAlertActionUseCase usecase.ApplyAlertActionUseCase
+	unsubscribeUseCase      usecase.UnsubscribeUseCase
+
+	confirmEmailUseCase               usecase.ConfirmEmailUseCase
+	expirePendingSubscriptionsUseCase usecase.ExpirePendingSubscriptionsUseCase
+
+	deliverOutboxUseCase usecase.DeliverOutboxUseCase
+	redriveOutboxUseCase usecase.RedriveOutboxUseCase
+
+	streamAlertsUseCase usecase.StreamAlertsUseCase
+
+	db *pkg.DB
 }
 
 func NewAPI(cfg *config.Config) (*API, error) {
+	if err := pkg.LoadEmailTemplates(cfg.Notification.TemplateDir); err != nil {
+		return nil, err
+	}
+
 	database, err := pkg.NewDB(&cfg.Database)
 	if err != nil {
 		return nil, err
 	}
 
 	alertRepo := db.NewAlertThresholdRepository(database)
+	holdingRepo := db.NewHoldingRepository(database)
+	historyRepo := db.NewAlertHistoryRepository(database)
+	settingsRepo := db.NewNotificationSettingsRepository(database)
 	coinMarketCapRepo := apiRepo.NewCoinMarketCapRepository(cfg)
 	coinGeckoRepo := apiRepo.NewCoinGeckoRepository(cfg)
-	emailNotifier := notifierRepo.NewEmailNotifier(&cfg.SMTP)
+	suppressionRepo := db.NewSuppressionRepository(database)
+	verifiedRepo := db.NewVerifiedEmailRepository(database)
+	outboxRepo := db.NewOutboxRepository(database)
+	actionSigner := pkg.NewActionSigner(cfg.ActionLink.BaseURL, cfg.ActionLink.Secret)
+	emailNotifier := notifierRepo.NewEmailNotifier(&cfg.SMTP, suppressionRepo, actionSigner)
+	pendingExpiry := time.Duration(cfg.OptIn.ExpiryHours) * time.Hour
+	emailVerifier := usecase.NewEmailVerifier(verifiedRepo, emailNotifier, actionSigner, pendingExpiry, cfg.OptIn.Required)
+	alertStream := pkg.NewAlertStream()
 
 	return &API{
 		config:                  cfg,
-		createAlertUseCase:      usecase.NewCreateAlertUseCase(alertRepo),
-		executeAlertScanUseCase: usecase.NewExecuteAlertScanUseCase(alertRepo, coinMarketCapRepo, coinGeckoRepo, emailNotifier),
-		db:                      database,
+		createAlertUseCase:      usecase.NewCreateAlertUseCase(alertRepo, emailVerifier),
+		executeAlertScanUseCase: usecase.NewExecuteAlertScanUseCase(alertRepo, historyRepo, settingsRepo, coinMarketCapRepo, coinGeckoRepo, outboxRepo, actionSigner, alertStream, cfg.Notification.GroupBySymbol),
+		listAlertsUseCase:       usecase.NewListAlertsUseCase(alertRepo),
+		updateAlertStateUseCase: usecase.NewUpdateAlertStateUseCase(alertRepo),
+
+		saveHoldingUseCase:          usecase.NewSaveHoldingUseCase(holdingRepo, emailVerifier),
+		savePortfolioAlertUseCase:   usecase.NewSavePortfolioAlertUseCase(holdingRepo, emailVerifier),
+		getPortfolioUseCase:         usecase.NewGetPortfolioUseCase(holdingRepo, coinMarketCapRepo),
+		executePortfolioScanUseCase: usecase.NewExecutePortfolioScanUseCase(holdingRepo, historyRepo, settingsRepo, coinMarketCapRepo, outboxRepo, emailVerifier, alertStream, cfg.Notification.GroupBySymbol),
+		sendDigestsUseCase:          usecase.NewSendDigestsUseCase(alertRepo, historyRepo, settingsRepo, coinMarketCapRepo, outboxRepo, cfg.Digest.Hour),
+
+		saveNotificationSettingsUseCase: usecase.NewSaveNotificationSettingsUseCase(settingsRepo),
+		deliverQueuedAlertsUseCase:      usecase.NewDeliverQueuedAlertsUseCase(settingsRepo, outboxRepo),
+
+		applyAlertActionUseCase: usecase.NewApplyAlertActionUseCase(alertRepo, actionSigner),
+		unsubscribeUseCase:      usecase.NewUnsubscribeUseCase(suppressionRepo, actionSigner),
+
+		confirmEmailUseCase:               usecase.NewConfirmEmailUseCase(alertRepo, verifiedRepo, actionSigner),
+		expirePendingSubscriptionsUseCase: usecase.NewExpirePendingSubscriptionsUseCase(alertRepo, pendingExpiry),
+
+		deliverOutboxUseCase: usecase.NewDeliverOutboxUseCase(outboxRepo, emailNotifier, cfg.Outbox.MaxAttempts, time.Duration(cfg.Outbox.BackoffSeconds)*time.Second),
+		redriveOutboxUseCase: usecase.NewRedriveOutboxUseCase(outboxRepo),
+
+		streamAlertsUseCase: usecase.NewStreamAlertsUseCase(historyRepo, alertStream),
+
+		db: database,
 	}, nil
 }
 
@@ -49,15 +143,64 @@ func (api *API) SetupRoutes() http.Handler {
 
 	mux.HandleFunc("/crypto_alert_api/execute", api.handleScanExecution)
 	mux.HandleFunc("/crypto_alert_api/create", api.handleCreate)
+	mux.HandleFunc("/crypto_alert_api/list", api.handleList)
+	mux.HandleFunc("/crypto_alert_api/state", api.handleUpdateState)
+	mux.HandleFunc("/crypto_alert_api/holdings", api.handleHoldings)
+	mux.HandleFunc("/crypto_alert_api/portfolio_alert", api.handlePortfolioAlert)
+	mux.HandleFunc("/crypto_alert_api/notification_settings", api.handleNotificationSettings)
+	mux.HandleFunc(pkg.ActionLinkPath, api.handleAlertAction)
+	mux.HandleFunc(pkg.UnsubscribePath, api.handleUnsubscribe)
+	mux.HandleFunc(pkg.ConfirmEmailPath, api.handleConfirmEmail)
+	mux.HandleFunc("/crypto_alert_api/admin/outbox/dead", api.requireAdmin(api.handleDeadLetters))
+	mux.HandleFunc("/crypto_alert_api/admin/outbox/redrive", api.requireAdmin(api.handleRedrive))
+	mux.HandleFunc("/crypto_alert_api/admin/alerts/stream", api.requireAdmin(api.handleAlertStream))
 
 	return corsMiddleware(mux)
 }
 
+// Jobs returns the background jobs to run alongside the HTTP server.
+func (api *API) Jobs() []scheduler.Job {
+	return []scheduler.Job{
+		{
+			Name:     "digests",
+			Interval: digestCheckInterval,
+			Run: func(now time.Time) error {
+				_, err := api.sendDigestsUseCase.Execute(now)
+				return err
+			},
+		},
+		{
+			Name:     "quiet_hours_queue",
+			Interval: queueCheckInterval,
+			Run: func(now time.Time) error {
+				_, err := api.deliverQueuedAlertsUseCase.Execute(now)
+				return err
+			},
+		},
+		{
+			Name:     "pending_subscriptions",
+			Interval: pendingCheckInterval,
+			Run: func(now time.Time) error {
+				_, err := api.expirePendingSubscriptionsUseCase.Execute(now)
+				return err
+			},
+		},
+		{
+			Name:     "outbox",
+			Interval: outboxCheckInterval,
+			Run: func(now time.Time) error {
+				_, err := api.deliverOutboxUseCase.Execute(now)
+				return err
+			},
+		},
+	}
+}
+
 func corsMiddleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Access-Control-Allow-Origin", "*")
 		w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
+		w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization")
 
 		if r.Method == http.MethodOptions {
 			w.WriteHeader(http.StatusOK)
@@ -80,8 +223,18 @@ func (api *API) handleScanExecution(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	portfolioAlerts, err := api.executePortfolioScanUseCase.Execute()
+	if err != nil {
+		http.Error(w, "Failed to execute portfolio scan", http.StatusInternalServerError)
+		return
+	}
+
+	if _, err := api.deliverOutboxUseCase.Execute(time.Now()); err != nil {
+		log.Printf("Error delivering the outbox after the scan: %v", err)
+	}
+
 	response := CheckAlertsResponse{
-		AlertsTriggered: len(alerts),
+		AlertsTriggered: len(alerts) + len(portfolioAlerts),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -107,10 +260,452 @@ func (api *API) handleCreate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	message := "Configuration saved successfully"
+	if alertThreshold.State == entity.AlertStatePending {
+		message = "Configuration saved, confirm the email address to start receiving alerts"
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(map[string]string{
 		"status":  "success",
-		"message": "Configuration saved successfully",
+		"message": message,
+	})
+}
+
+func (api *API) handleList(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	thresholds, err := api.listAlertsUseCase.Execute(r.URL.Query().Get("email"))
+	if err != nil {
+		log.Printf("Error listing alerts: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(thresholds)
+}
+
+func (api *API) handleUpdateState(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request UpdateStateRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := api.updateAlertStateUseCase.Execute(request.ID, request.Email, request.State)
+	if errors.Is(err, db.ErrThresholdNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, db.ErrInvalidStateTransition) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		log.Printf("Error updating alert state: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Alert state updated successfully",
+	})
+}
+
+func (api *API) handleHoldings(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		portfolio, err := api.getPortfolioUseCase.Execute(r.URL.Query().Get("email"))
+		if err != nil {
+			log.Printf("Error getting portfolio: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(portfolio)
+
+	case http.MethodPost:
+		var holding entity.Holding
+		if err := json.NewDecoder(r.Body).Decode(&holding); err != nil {
+			http.Error(w, "Invalid request body", http.StatusBadRequest)
+			return
+		}
+
+		if err := api.saveHoldingUseCase.Execute(&holding); err != nil {
+			log.Printf("Error saving holding: %v", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(map[string]string{
+			"status":  "success",
+			"message": "Holding saved successfully",
+		})
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (api *API) handlePortfolioAlert(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var portfolioAlert entity.PortfolioAlert
+	if err := json.NewDecoder(r.Body).Decode(&portfolioAlert); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.savePortfolioAlertUseCase.Execute(&portfolioAlert); err != nil {
+		log.Printf("Error saving portfolio alert: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Portfolio alert saved successfully",
+	})
+}
+
+func (api *API) handleNotificationSettings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var settings entity.NotificationSettings
+	if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := api.saveNotificationSettingsUseCase.Execute(&settings); err != nil {
+		log.Printf("Error saving notification settings: %v", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]string{
+		"status":  "success",
+		"message": "Notification settings saved successfully",
+	})
+}
+
+// handleAlertAction serves the links in alert emails. GET only asks for
+// confirmation, so link scanners that prefetch URLs change nothing; the
+// action runs on the POST from the confirmation page.
+func (api *API) handleAlertAction(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		token := r.URL.Query().Get("token")
+		action, err := api.applyAlertActionUseCase.Verify(token)
+		if err != nil {
+			l := pageLocale(r, nil)
+			writeActionPage(w, http.StatusBadRequest, l, l.T("page.invalid.title"), l.T("page.invalid"), "")
+			return
+		}
+
+		l := pageLocale(r, action)
+		form := fmt.Sprintf("<form method='POST' action='%s'><input type='hidden' name='token' value='%s'/><button type='submit'>%s</button></form>",
+			pkg.ActionLinkPath, html.EscapeString(token), html.EscapeString(l.T("page.action.button")))
+		writeActionPage(w, http.StatusOK, l, l.T("page.action.title"),
+			l.T("page.action.question", html.EscapeString(l.T("page.action."+action.Action, action.Symbol))), form)
+
+	case http.MethodPost:
+		action, err := api.applyAlertActionUseCase.Execute(r.FormValue("token"))
+		l := pageLocale(r, action)
+		if errors.Is(err, pkg.ErrInvalidActionToken) || errors.Is(err, pkg.ErrExpiredActionToken) {
+			writeActionPage(w, http.StatusBadRequest, l, l.T("page.invalid.title"), l.T("page.invalid"), "")
+			return
+		}
+		if errors.Is(err, db.ErrThresholdNotFound) {
+			writeActionPage(w, http.StatusNotFound, l, l.T("page.action.not_found.title"), l.T("page.action.not_found"), "")
+			return
+		}
+		if err != nil {
+			log.Printf("Error applying alert action: %v", err)
+			writeActionPage(w, http.StatusInternalServerError, l, l.T("page.error.title"), l.T("page.error"), "")
+			return
+		}
+
+		writeActionPage(w, http.StatusOK, l, l.T("page.action.done.title"),
+			l.T("page.action.done", html.EscapeString(l.T("page.action."+action.Action, action.Symbol))), "")
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleUnsubscribe implements RFC 8058: mail clients POST to the
+// List-Unsubscribe URL with the token in the query string and expect no
+// further interaction. A GET from a browser gets a confirmation page.
+func (api *API) handleUnsubscribe(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		token := r.URL.Query().Get("token")
+		action, err := api.unsubscribeUseCase.Verify(token)
+		if err != nil {
+			l := pageLocale(r, nil)
+			writeActionPage(w, http.StatusBadRequest, l, l.T("page.invalid.title"), l.T("page.invalid"), "")
+			return
+		}
+
+		l := pageLocale(r, action)
+		form := fmt.Sprintf("<form method='POST' action='%s?token=%s'><button type='submit'>%s</button></form>",
+			pkg.UnsubscribePath, html.EscapeString(url.QueryEscape(token)), html.EscapeString(l.T("page.unsubscribe.button")))
+		writeActionPage(w, http.StatusOK, l, l.T("page.unsubscribe.title"),
+			l.T("page.unsubscribe.question", html.EscapeString(action.Email)), form)
+
+	case http.MethodPost:
+		action, err := api.unsubscribeUseCase.Execute(r.FormValue("token"))
+		l := pageLocale(r, action)
+		if errors.Is(err, pkg.ErrInvalidActionToken) || errors.Is(err, pkg.ErrExpiredActionToken) {
+			writeActionPage(w, http.StatusBadRequest, l, l.T("page.invalid.title"), l.T("page.invalid"), "")
+			return
+		}
+		if err != nil {
+			log.Printf("Error unsubscribing: %v", err)
+			writeActionPage(w, http.StatusInternalServerError, l, l.T("page.error.title"), l.T("page.error"), "")
+			return
+		}
+
+		writeActionPage(w, http.StatusOK, l, l.T("page.unsubscribe.done.title"),
+			l.T("page.unsubscribe.done", html.EscapeString(action.Email)), "")
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (api *API) handleConfirmEmail(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		token := r.URL.Query().Get("token")
+		action, err := api.confirmEmailUseCase.Verify(token)
+		if err != nil {
+			l := pageLocale(r, nil)
+			writeActionPage(w, http.StatusBadRequest, l, l.T("page.invalid.title"), l.T("page.confirm.invalid"), "")
+			return
+		}
+
+		l := pageLocale(r, action)
+		form := fmt.Sprintf("<form method='POST' action='%s'><input type='hidden' name='token' value='%s'/><button type='submit'>%s</button></form>",
+			pkg.ConfirmEmailPath, html.EscapeString(token), html.EscapeString(l.T("page.confirm.button")))
+		writeActionPage(w, http.StatusOK, l, l.T("page.confirm.title"),
+			l.T("page.confirm.question", html.EscapeString(action.Email)), form)
+
+	case http.MethodPost:
+		action, activated, err := api.confirmEmailUseCase.Execute(r.FormValue("token"))
+		l := pageLocale(r, action)
+		if errors.Is(err, pkg.ErrInvalidActionToken) || errors.Is(err, pkg.ErrExpiredActionToken) {
+			writeActionPage(w, http.StatusBadRequest, l, l.T("page.invalid.title"), l.T("page.confirm.invalid"), "")
+			return
+		}
+		if err != nil {
+			log.Printf("Error confirming email: %v", err)
+			writeActionPage(w, http.StatusInternalServerError, l, l.T("page.error.title"), l.T("page.error"), "")
+			return
+		}
+
+		writeActionPage(w, http.StatusOK, l, l.T("page.confirm.done.title"), l.T("page.confirm.done", activated), "")
+
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// pageLocale picks the language of the subscription a link was sent for.
+// Links without one, such as the List-Unsubscribe header, use the
+// browser's language instead.
+func pageLocale(r *http.Request, action *entity.AlertAction) *pkg.Locale {
+	if action != nil && action.Locale != "" {
+		return pkg.GetLocale(action.Locale)
+	}
+	return pkg.GetLocale(pkg.PreferredLocale(r.Header.Get("Accept-Language")))
+}
+
+// writeActionPage writes a page around message, which is HTML and must
+// have its user supplied parts escaped.
+func writeActionPage(w http.ResponseWriter, status int, l *pkg.Locale, title string, message string, form string) {
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	fmt.Fprintf(w, "<html lang='%s'><body style='font-family: Arial, sans-serif; line-height: 1.6; color: #333;'><h2>%s</h2><p>%s</p>%s<p>%s</p></body></html>",
+		l.Tag, html.EscapeString(title), message, form, html.EscapeString(l.T("page.team")))
+}
+
+// requireAdmin only lets requests carrying the configured admin token
+// through. Admin endpoints are disabled when no token is configured.
+func (api *API) requireAdmin(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		token := api.config.Admin.Token
+		provided := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if token == "" || subtle.ConstantTimeCompare([]byte(provided), []byte(token)) != 1 {
+			http.Error(w, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next(w, r)
+	}
+}
+
+func (api *API) handleDeadLetters(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	messages, err := api.redriveOutboxUseCase.ListDead()
+	if err != nil {
+		log.Printf("Error listing dead letters: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(messages)
+}
+
+func (api *API) handleRedrive(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	var request RedriveRequest
+	if err := json.NewDecoder(r.Body).Decode(&request); err != nil && !errors.Is(err, io.EOF) {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	redriven, err := api.redriveOutboxUseCase.Execute(request.IDs)
+	if err != nil {
+		log.Printf("Error re-driving dead letters: %v", err)
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(map[string]int{
+		"redriven": redriven,
 	})
 }
+
+// handleAlertStream pushes alerts as Server-Sent Events while the scans
+// record them, optionally only those of one email or symbol. Clients
+// resuming with Last-Event-ID first get what they missed from the alert
+// history.
+func (api *API) handleAlertStream(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+	var lastID int64
+	if lastEventID != "" {
+		var err error
+		if lastID, err = strconv.ParseInt(lastEventID, 10, 64); err != nil || lastID < 0 {
+			http.Error(w, "Invalid last event ID", http.StatusBadRequest)
+			return
+		}
+	}
+
+	subscription := api.streamAlertsUseCase.Subscribe(r.URL.Query().Get("email"), r.URL.Query().Get("symbol"))
+	defer api.streamAlertsUseCase.Unsubscribe(subscription)
+
+	controller := http.NewResponseController(w)
+	controller.SetWriteDeadline(time.Time{})
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	send := func(entry *entity.AlertHistory) error {
+		data, err := json.Marshal(entry)
+		if err != nil {
+			return err
+		}
+		if _, err := fmt.Fprintf(w, "id: %d\nevent: alert\ndata: %s\n\n", entry.ID, data); err != nil {
+			return err
+		}
+		lastID = entry.ID
+		return nil
+	}
+
+	if lastID > 0 {
+		for {
+			entries, err := api.streamAlertsUseCase.Replay(subscription, lastID)
+			if err != nil {
+				log.Printf("Error replaying alert history after %d: %v", lastID, err)
+				return
+			}
+			if len(entries) == 0 {
+				break
+			}
+			for _, entry := range entries {
+				if err := send(entry); err != nil {
+					return
+				}
+			}
+		}
+	}
+	if err := controller.Flush(); err != nil {
+		return
+	}
+
+	heartbeat := time.NewTicker(streamHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+
+		case entry, ok := <-subscription.Events:
+			// A closed channel means the client fell behind; it reconnects
+			// with its last event ID and catches up from the history.
+			if !ok {
+				return
+			}
+			if entry.ID <= lastID {
+				continue
+			}
+			if err := send(entry); err != nil {
+				return
+			}
+
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+
+		if err := controller.Flush(); err != nil {
+			return
+		}
+	}
+}
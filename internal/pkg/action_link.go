@@ -0,0 +1,163 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ActionLinkTTL is how long the links in an alert email stay valid. The
+// unsubscribe link lasts longer since mail clients keep showing it.
+const (
+	ActionLinkTTL      = 30 * 24 * time.Hour
+	UnsubscribeLinkTTL = 365 * 24 * time.Hour
+)
+
+const (
+	ActionLinkPath   = "/crypto_alert_api/action"
+	UnsubscribePath  = "/crypto_alert_api/unsubscribe"
+	ConfirmEmailPath = "/crypto_alert_api/confirm"
+)
+
+var (
+	ErrInvalidActionToken = errors.New("invalid action link")
+	ErrExpiredActionToken = errors.New("action link expired")
+)
+
+// ActionLink is labelled when the email is rendered, in the language of
+// the subscription.
+type ActionLink struct {
+	Action string
+	Symbol string
+	URL    string
+}
+
+// ActionSigner builds and verifies the HMAC-signed links that let users act
+// on an alert without logging in. A nil signer produces no links.
+type ActionSigner struct {
+	baseURL string
+	secret  []byte
+}
+
+func NewActionSigner(baseURL string, secret string) *ActionSigner {
+	if baseURL == "" || secret == "" {
+		return nil
+	}
+	return &ActionSigner{
+		baseURL: strings.TrimRight(baseURL, "/"),
+		secret:  []byte(secret),
+	}
+}
+
+func (s *ActionSigner) Sign(action entity.AlertAction) string {
+	payload, _ := json.Marshal(action)
+	encoded := base64.RawURLEncoding.EncodeToString(payload)
+	return encoded + "." + base64.RawURLEncoding.EncodeToString(s.mac(encoded))
+}
+
+func (s *ActionSigner) Verify(token string, now time.Time) (*entity.AlertAction, error) {
+	if s == nil {
+		return nil, ErrInvalidActionToken
+	}
+
+	encoded, signature, found := strings.Cut(token, ".")
+	if !found {
+		return nil, ErrInvalidActionToken
+	}
+
+	expected, err := base64.RawURLEncoding.DecodeString(signature)
+	if err != nil || !hmac.Equal(expected, s.mac(encoded)) {
+		return nil, ErrInvalidActionToken
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, ErrInvalidActionToken
+	}
+
+	var action entity.AlertAction
+	if err := json.Unmarshal(payload, &action); err != nil {
+		return nil, ErrInvalidActionToken
+	}
+	if now.Unix() > action.ExpiresAt {
+		return nil, ErrExpiredActionToken
+	}
+
+	return &action, nil
+}
+
+// Links returns the snooze, disable and unsubscribe links for an alert. The
+// disable link is only offered for alerts raised by a single rule.
+func (s *ActionSigner) Links(thresholdID int64, ruleID int64, email string, symbol string, locale string, now time.Time) []ActionLink {
+	if s == nil {
+		return nil
+	}
+
+	expiresAt := now.Add(ActionLinkTTL).Unix()
+	link := func(action string, ruleID int64) ActionLink {
+		token := s.Sign(entity.AlertAction{
+			Action:      action,
+			ThresholdID: thresholdID,
+			RuleID:      ruleID,
+			Email:       email,
+			Symbol:      symbol,
+			Locale:      locale,
+			ExpiresAt:   expiresAt,
+		})
+		return ActionLink{Action: action, Symbol: symbol, URL: s.baseURL + ActionLinkPath + "?token=" + url.QueryEscape(token)}
+	}
+
+	links := []ActionLink{
+		link(entity.AlertActionSnooze1h, 0),
+		link(entity.AlertActionSnooze24h, 0),
+	}
+	if ruleID != 0 {
+		links = append(links, link(entity.AlertActionDisableRule, ruleID))
+	}
+	links = append(links, link(entity.AlertActionUnsubscribeSymbol, 0))
+
+	return links
+}
+
+// UnsubscribeURL returns the one-click link that stops every email to the
+// address, or an empty string when links are disabled.
+func (s *ActionSigner) UnsubscribeURL(email string, now time.Time) string {
+	if s == nil {
+		return ""
+	}
+
+	token := s.Sign(entity.AlertAction{
+		Action:    entity.AlertActionUnsubscribeAll,
+		Email:     email,
+		ExpiresAt: now.Add(UnsubscribeLinkTTL).Unix(),
+	})
+	return s.baseURL + UnsubscribePath + "?token=" + url.QueryEscape(token)
+}
+
+// ConfirmationURL returns the double opt-in link for a new address, valid
+// for as long as its pending subscriptions.
+func (s *ActionSigner) ConfirmationURL(email string, locale string, ttl time.Duration, now time.Time) string {
+	if s == nil {
+		return ""
+	}
+
+	token := s.Sign(entity.AlertAction{
+		Action:    entity.AlertActionConfirmEmail,
+		Email:     email,
+		Locale:    locale,
+		ExpiresAt: now.Add(ttl).Unix(),
+	})
+	return s.baseURL + ConfirmEmailPath + "?token=" + url.QueryEscape(token)
+}
+
+func (s *ActionSigner) mac(data string) []byte {
+	h := hmac.New(sha256.New, s.secret)
+	h.Write([]byte(data))
+	return h.Sum(nil)
+}
@@ -0,0 +1,88 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"strings"
+	"sync"
+)
+
+// alertSubscriptionBuffer is how many alerts a subscriber may fall behind
+// before it is dropped.
+const alertSubscriptionBuffer = 64
+
+// AlertStream fans recorded alerts out to the clients of the streaming
+// endpoint. Subscribers that fall behind are dropped rather than blocking
+// the scan, and are expected to resume from the alert history.
+type AlertStream struct {
+	mu          sync.Mutex
+	subscribers map[*AlertSubscription]struct{}
+}
+
+// AlertSubscription receives the alerts matching its filters, an empty
+// filter matching every alert. Events is closed once the subscription is
+// dropped or cancelled.
+type AlertSubscription struct {
+	Email  string
+	Symbol string
+	Events <-chan *entity.AlertHistory
+
+	events chan *entity.AlertHistory
+}
+
+func NewAlertStream() *AlertStream {
+	return &AlertStream{subscribers: make(map[*AlertSubscription]struct{})}
+}
+
+func (s *AlertStream) Subscribe(email string, symbol string) *AlertSubscription {
+	events := make(chan *entity.AlertHistory, alertSubscriptionBuffer)
+	subscription := &AlertSubscription{
+		Email:  strings.TrimSpace(email),
+		Symbol: strings.ToUpper(strings.TrimSpace(symbol)),
+		Events: events,
+		events: events,
+	}
+
+	s.mu.Lock()
+	s.subscribers[subscription] = struct{}{}
+	s.mu.Unlock()
+
+	return subscription
+}
+
+func (s *AlertStream) Unsubscribe(subscription *AlertSubscription) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.remove(subscription)
+}
+
+// Publish hands the entry to every matching subscriber without waiting for
+// any of them.
+func (s *AlertStream) Publish(entry *entity.AlertHistory) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for subscription := range s.subscribers {
+		if !subscription.Matches(entry) {
+			continue
+		}
+		select {
+		case subscription.events <- entry:
+		default:
+			s.remove(subscription)
+		}
+	}
+}
+
+func (s *AlertStream) remove(subscription *AlertSubscription) {
+	if _, ok := s.subscribers[subscription]; ok {
+		delete(s.subscribers, subscription)
+		close(subscription.events)
+	}
+}
+
+func (sub *AlertSubscription) Matches(entry *entity.AlertHistory) bool {
+	if sub.Email != "" && sub.Email != entry.Email {
+		return false
+	}
+	return sub.Symbol == "" || sub.Symbol == entry.Symbol
+}
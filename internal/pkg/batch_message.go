@@ -0,0 +1,62 @@
+package pkg
+
+import "strings"
+
+// FormatBatchEmailSubject summarizes several alerts fired for the same
+// recipient in one scan. The alerts are expected to share a locale, as
+// batches are grouped by it. A single alert keeps its own subject.
+func FormatBatchEmailSubject(messages []AlertMessage) string {
+	if len(messages) == 1 {
+		return FormatEmailSubject(messages[0])
+	}
+
+	locale := GetLocale(messages[0].Locale)
+	return "🔔 " + locale.T("batch.subject", len(messages), strings.Join(batchSymbols(messages), ", "))
+}
+
+// FormatBatchEmailBody lists every condition that fired, followed by the
+// charts of each symbol and the Fear & Greed index, each shown only once.
+func FormatBatchEmailBody(messages []AlertMessage) (EmailBody, error) {
+	if len(messages) == 1 {
+		return FormatEmailBody(messages[0])
+	}
+
+	locale := GetLocale(messages[0].Locale)
+	data := batchEmailData{L: locale}
+	charted := make(map[string]bool)
+	for _, message := range messages {
+		item := alertEmailData{AlertMessage: message, L: locale}
+		data.Messages = append(data.Messages, item)
+
+		if message.HistoricalData != nil && !charted[message.Symbol] {
+			charted[message.Symbol] = true
+			data.Charted = append(data.Charted, item)
+		}
+		if message.FearGreedClass != "" && data.FearGreed == nil {
+			data.FearGreed = &item
+		}
+	}
+
+	return renderEmail("batch", data)
+}
+
+// batchEmailData lists every alert, then the charts of each symbol and the
+// Fear & Greed index, each shown only once.
+type batchEmailData struct {
+	Messages  []alertEmailData
+	Charted   []alertEmailData
+	FearGreed *alertEmailData
+	L         *Locale
+}
+
+func batchSymbols(messages []AlertMessage) []string {
+	seen := make(map[string]bool)
+	var symbols []string
+	for _, message := range messages {
+		if !seen[message.Symbol] {
+			seen[message.Symbol] = true
+			symbols = append(symbols, message.Symbol)
+		}
+	}
+	return symbols
+}
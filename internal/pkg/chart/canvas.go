@@ -0,0 +1,352 @@
+// Package chart draws the charts embedded in alert emails without any
+// external service. Shapes are recorded on a Canvas and encoded either as
+// PNG, which every mail client displays, or as SVG.
+package chart
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/png"
+	"math"
+	"strings"
+)
+
+type Point struct {
+	X float64
+	Y float64
+}
+
+// Text anchors, as in SVG.
+const (
+	AnchorStart  = "start"
+	AnchorMiddle = "middle"
+	AnchorEnd    = "end"
+)
+
+type shape interface {
+	rasterize(img *image.RGBA)
+	svg(out *strings.Builder)
+}
+
+type Canvas struct {
+	Width      int
+	Height     int
+	Background color.RGBA
+	shapes     []shape
+}
+
+func NewCanvas(width int, height int, background color.RGBA) *Canvas {
+	return &Canvas{Width: width, Height: height, Background: background}
+}
+
+func (c *Canvas) Rect(x, y, width, height float64, fill color.RGBA) {
+	c.shapes = append(c.shapes, rectShape{x, y, width, height, fill})
+}
+
+func (c *Canvas) Line(from Point, to Point, width float64, stroke color.RGBA) {
+	c.Polyline([]Point{from, to}, width, stroke)
+}
+
+func (c *Canvas) Polyline(points []Point, width float64, stroke color.RGBA) {
+	c.shapes = append(c.shapes, polylineShape{points, width, stroke})
+}
+
+func (c *Canvas) Polygon(points []Point, fill color.RGBA) {
+	c.shapes = append(c.shapes, polygonShape{points, fill})
+}
+
+// Sector fills the ring between the two radii from start to end, in
+// radians measured clockwise from the positive x axis (screen coordinates).
+func (c *Canvas) Sector(center Point, inner, outer, start, end float64, fill color.RGBA) {
+	c.shapes = append(c.shapes, sectorShape{center, inner, outer, start, end, fill})
+}
+
+// Circle fills a disc.
+func (c *Canvas) Circle(center Point, radius float64, fill color.RGBA) {
+	c.Sector(center, 0, radius, 0, 2*math.Pi, fill)
+}
+
+// Text writes a label whose baseline sits at y. Size is the cap height in
+// pixels.
+func (c *Canvas) Text(x, y float64, text string, size float64, fill color.RGBA, anchor string) {
+	c.shapes = append(c.shapes, textShape{x, y, text, size, fill, anchor})
+}
+
+func (c *Canvas) PNG() ([]byte, error) {
+	img := image.NewRGBA(image.Rect(0, 0, c.Width, c.Height))
+	for i := 0; i < len(img.Pix); i += 4 {
+		img.Pix[i], img.Pix[i+1], img.Pix[i+2], img.Pix[i+3] = c.Background.R, c.Background.G, c.Background.B, 255
+	}
+
+	for _, s := range c.shapes {
+		s.rasterize(img)
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("erro ao gerar imagem do gráfico: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+func (c *Canvas) SVG() []byte {
+	var out strings.Builder
+	fmt.Fprintf(&out, `<svg xmlns="http://www.w3.org/2000/svg" width="%d" height="%d" viewBox="0 0 %d %d">`, c.Width, c.Height, c.Width, c.Height)
+	fmt.Fprintf(&out, `<rect width="100%%" height="100%%" fill="%s"/>`, svgColor(c.Background))
+	for _, s := range c.shapes {
+		s.svg(&out)
+	}
+	out.WriteString(`</svg>`)
+	return []byte(out.String())
+}
+
+type rectShape struct {
+	x, y, width, height float64
+	fill                color.RGBA
+}
+
+func (r rectShape) rasterize(img *image.RGBA) {
+	x0, y0 := int(math.Round(r.x)), int(math.Round(r.y))
+	x1, y1 := int(math.Round(r.x+r.width)), int(math.Round(r.y+r.height))
+	for y := y0; y < y1; y++ {
+		for x := x0; x < x1; x++ {
+			blend(img, x, y, r.fill, 1)
+		}
+	}
+}
+
+func (r rectShape) svg(out *strings.Builder) {
+	fmt.Fprintf(out, `<rect x="%.1f" y="%.1f" width="%.1f" height="%.1f" %s/>`, r.x, r.y, r.width, r.height, svgFill(r.fill))
+}
+
+type polylineShape struct {
+	points []Point
+	width  float64
+	stroke color.RGBA
+}
+
+// rasterize draws each segment with coverage based on the distance of the
+// pixel center to the segment, which gives anti-aliased edges.
+func (p polylineShape) rasterize(img *image.RGBA) {
+	half := p.width / 2
+	for i := 1; i < len(p.points); i++ {
+		a, b := p.points[i-1], p.points[i]
+		minX := int(math.Floor(math.Min(a.X, b.X) - half - 1))
+		maxX := int(math.Ceil(math.Max(a.X, b.X) + half + 1))
+		minY := int(math.Floor(math.Min(a.Y, b.Y) - half - 1))
+		maxY := int(math.Ceil(math.Max(a.Y, b.Y) + half + 1))
+
+		for y := minY; y <= maxY; y++ {
+			for x := minX; x <= maxX; x++ {
+				d := segmentDistance(Point{float64(x) + 0.5, float64(y) + 0.5}, a, b)
+				coverage := clamp(half+0.5-d, 0, 1)
+				if coverage > 0 && !p.coveredBefore(i, x, y) {
+					blend(img, x, y, p.stroke, coverage)
+				}
+			}
+		}
+	}
+}
+
+// coveredBefore avoids blending a translucent stroke twice where two
+// consecutive segments meet.
+func (p polylineShape) coveredBefore(segment int, x, y int) bool {
+	if segment < 2 || p.stroke.A == 255 {
+		return false
+	}
+	center := Point{float64(x) + 0.5, float64(y) + 0.5}
+	return segmentDistance(center, p.points[segment-2], p.points[segment-1]) <= p.width/2
+}
+
+func (p polylineShape) svg(out *strings.Builder) {
+	fmt.Fprintf(out, `<polyline points="%s" fill="none" %s stroke-width="%.1f" stroke-linejoin="round" stroke-linecap="round"/>`,
+		svgPoints(p.points), svgStroke(p.stroke), p.width)
+}
+
+type polygonShape struct {
+	points []Point
+	fill   color.RGBA
+}
+
+// rasterize fills the polygon with the even-odd rule, sampling each pixel
+// at its center.
+func (p polygonShape) rasterize(img *image.RGBA) {
+	if len(p.points) < 3 {
+		return
+	}
+
+	minY, maxY := p.points[0].Y, p.points[0].Y
+	for _, point := range p.points {
+		minY, maxY = math.Min(minY, point.Y), math.Max(maxY, point.Y)
+	}
+
+	var crossings []float64
+	for y := int(math.Floor(minY)); y <= int(math.Ceil(maxY)); y++ {
+		sampleY := float64(y) + 0.5
+		crossings = crossings[:0]
+		for i := range p.points {
+			a, b := p.points[i], p.points[(i+1)%len(p.points)]
+			if (a.Y <= sampleY) != (b.Y <= sampleY) {
+				crossings = append(crossings, a.X+(sampleY-a.Y)*(b.X-a.X)/(b.Y-a.Y))
+			}
+		}
+		sortFloats(crossings)
+		for i := 0; i+1 < len(crossings); i += 2 {
+			for x := int(math.Ceil(crossings[i] - 0.5)); float64(x)+0.5 <= crossings[i+1]; x++ {
+				blend(img, x, y, p.fill, 1)
+			}
+		}
+	}
+}
+
+func (p polygonShape) svg(out *strings.Builder) {
+	fmt.Fprintf(out, `<polygon points="%s" %s/>`, svgPoints(p.points), svgFill(p.fill))
+}
+
+type sectorShape struct {
+	center       Point
+	inner, outer float64
+	start, end   float64
+	fill         color.RGBA
+}
+
+func (s sectorShape) rasterize(img *image.RGBA) {
+	full := s.end-s.start >= 2*math.Pi
+	for y := int(s.center.Y - s.outer - 1); y <= int(s.center.Y+s.outer+1); y++ {
+		for x := int(s.center.X - s.outer - 1); x <= int(s.center.X+s.outer+1); x++ {
+			dx, dy := float64(x)+0.5-s.center.X, float64(y)+0.5-s.center.Y
+			r := math.Hypot(dx, dy)
+			coverage := math.Min(clamp(s.outer+0.5-r, 0, 1), clamp(r-s.inner+0.5, 0, 1))
+			if s.inner == 0 {
+				coverage = clamp(s.outer+0.5-r, 0, 1)
+			}
+			if coverage == 0 {
+				continue
+			}
+			if !full && !angleWithin(math.Atan2(dy, dx), s.start, s.end) {
+				continue
+			}
+			blend(img, x, y, s.fill, coverage)
+		}
+	}
+}
+
+func (s sectorShape) svg(out *strings.Builder) {
+	if s.end-s.start >= 2*math.Pi {
+		fmt.Fprintf(out, `<circle cx="%.1f" cy="%.1f" r="%.1f" %s/>`, s.center.X, s.center.Y, s.outer, svgFill(s.fill))
+		return
+	}
+
+	polar := func(radius, angle float64) Point {
+		return Point{s.center.X + radius*math.Cos(angle), s.center.Y + radius*math.Sin(angle)}
+	}
+	largeArc := 0
+	if s.end-s.start > math.Pi {
+		largeArc = 1
+	}
+	a, b := polar(s.outer, s.start), polar(s.outer, s.end)
+	c, d := polar(s.inner, s.end), polar(s.inner, s.start)
+	fmt.Fprintf(out, `<path d="M%.1f %.1fA%.1f %.1f 0 %d 1 %.1f %.1fL%.1f %.1fA%.1f %.1f 0 %d 0 %.1f %.1fZ" %s/>`,
+		a.X, a.Y, s.outer, s.outer, largeArc, b.X, b.Y, c.X, c.Y, s.inner, s.inner, largeArc, d.X, d.Y, svgFill(s.fill))
+}
+
+type textShape struct {
+	x, y   float64
+	text   string
+	size   float64
+	fill   color.RGBA
+	anchor string
+}
+
+func (t textShape) rasterize(img *image.RGBA) {
+	scale := max(1, int(math.Round(t.size/glyphHeight)))
+	width := float64(textWidth(t.text, scale))
+
+	x := t.x
+	switch t.anchor {
+	case AnchorMiddle:
+		x -= width / 2
+	case AnchorEnd:
+		x -= width
+	}
+
+	drawText(img, int(math.Round(x)), int(math.Round(t.y))-glyphHeight*scale, t.text, scale, t.fill)
+}
+
+func (t textShape) svg(out *strings.Builder) {
+	fmt.Fprintf(out, `<text x="%.1f" y="%.1f" font-family="Arial, sans-serif" font-size="%.1f" text-anchor="%s" %s>%s</text>`,
+		t.x, t.y, t.size*1.4, t.anchor, svgFill(t.fill), svgEscape(t.text))
+}
+
+func blend(img *image.RGBA, x, y int, c color.RGBA, coverage float64) {
+	if !(image.Point{x, y}.In(img.Rect)) {
+		return
+	}
+	alpha := float64(c.A) / 255 * coverage
+	i := img.PixOffset(x, y)
+	img.Pix[i] = uint8(float64(img.Pix[i])*(1-alpha) + float64(c.R)*alpha)
+	img.Pix[i+1] = uint8(float64(img.Pix[i+1])*(1-alpha) + float64(c.G)*alpha)
+	img.Pix[i+2] = uint8(float64(img.Pix[i+2])*(1-alpha) + float64(c.B)*alpha)
+	img.Pix[i+3] = 255
+}
+
+func segmentDistance(p, a, b Point) float64 {
+	dx, dy := b.X-a.X, b.Y-a.Y
+	lengthSquared := dx*dx + dy*dy
+	t := 0.0
+	if lengthSquared > 0 {
+		t = clamp(((p.X-a.X)*dx+(p.Y-a.Y)*dy)/lengthSquared, 0, 1)
+	}
+	return math.Hypot(p.X-(a.X+t*dx), p.Y-(a.Y+t*dy))
+}
+
+func angleWithin(angle, start, end float64) bool {
+	for angle < start {
+		angle += 2 * math.Pi
+	}
+	return angle <= end
+}
+
+func clamp(value, low, high float64) float64 {
+	return math.Max(low, math.Min(high, value))
+}
+
+func sortFloats(values []float64) {
+	for i := 1; i < len(values); i++ {
+		for j := i; j > 0 && values[j] < values[j-1]; j-- {
+			values[j], values[j-1] = values[j-1], values[j]
+		}
+	}
+}
+
+func svgColor(c color.RGBA) string {
+	return fmt.Sprintf("#%02x%02x%02x", c.R, c.G, c.B)
+}
+
+func svgFill(c color.RGBA) string {
+	if c.A == 255 {
+		return fmt.Sprintf(`fill="%s"`, svgColor(c))
+	}
+	return fmt.Sprintf(`fill="%s" fill-opacity="%.2f"`, svgColor(c), float64(c.A)/255)
+}
+
+func svgStroke(c color.RGBA) string {
+	if c.A == 255 {
+		return fmt.Sprintf(`stroke="%s"`, svgColor(c))
+	}
+	return fmt.Sprintf(`stroke="%s" stroke-opacity="%.2f"`, svgColor(c), float64(c.A)/255)
+}
+
+func svgPoints(points []Point) string {
+	parts := make([]string, len(points))
+	for i, p := range points {
+		parts[i] = fmt.Sprintf("%.1f,%.1f", p.X, p.Y)
+	}
+	return strings.Join(parts, " ")
+}
+
+func svgEscape(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
@@ -0,0 +1,196 @@
+package chart
+
+import (
+	"image/color"
+	"math"
+	"strconv"
+)
+
+const (
+	plotWidth   = 800
+	plotHeight  = 400
+	gaugeWidth  = 450
+	gaugeHeight = 280
+
+	marginLeft   = 100
+	marginRight  = 24
+	marginTop    = 24
+	marginBottom = 44
+	yTickCount   = 5
+)
+
+var (
+	backgroundColor = color.RGBA{0x2D, 0x37, 0x48, 255}
+	gridColor       = color.RGBA{255, 255, 255, 26}
+	tickColor       = color.RGBA{200, 200, 200, 255}
+	lineColor       = color.RGBA{99, 102, 241, 255}
+	areaColor       = color.RGBA{99, 102, 241, 26}
+	barColor        = color.RGBA{34, 197, 94, 153}
+	needleColor     = color.RGBA{255, 255, 255, 255}
+
+	// gaugeColors run from extreme fear to extreme greed.
+	gaugeColors = []color.RGBA{
+		{0xEA, 0x39, 0x43, 255},
+		{0xF5, 0x9E, 0x0B, 255},
+		{0xEA, 0xB3, 0x08, 255},
+		{0x93, 0xD9, 0x00, 255},
+		{0x16, 0xC7, 0x84, 255},
+	}
+)
+
+// Series is the data behind a line or bar chart. Labels name the x axis
+// positions, an empty label leaving that position unlabeled, and Format
+// prints the y axis ticks given the step between them.
+type Series struct {
+	Values []float64
+	Labels []string
+	Format func(tick float64, step float64) string
+}
+
+// plot maps series values onto the drawing area shared by line and bar
+// charts.
+type plot struct {
+	canvas   *Canvas
+	min, max float64
+	count    int
+}
+
+func newPlot(series Series, fromZero bool) *plot {
+	low, high := series.Values[0], series.Values[0]
+	for _, value := range series.Values {
+		low, high = math.Min(low, value), math.Max(high, value)
+	}
+	if fromZero {
+		low = math.Min(low, 0)
+	}
+	if high == low {
+		pad := math.Abs(high) * 0.05
+		if pad == 0 {
+			pad = 1
+		}
+		low, high = low-pad, high+pad
+	}
+
+	step := niceStep((high - low) / (yTickCount - 1))
+	p := &plot{
+		canvas: NewCanvas(plotWidth, plotHeight, backgroundColor),
+		min:    math.Floor(low/step) * step,
+		max:    math.Ceil(high/step) * step,
+		count:  len(series.Values),
+	}
+	if fromZero && low >= 0 {
+		p.min = 0
+	}
+
+	for tick := p.min; tick <= p.max+step/2; tick += step {
+		y := p.y(tick)
+		p.canvas.Line(Point{marginLeft, y}, Point{plotWidth - marginRight, y}, 1, gridColor)
+		p.canvas.Text(marginLeft-10, y+5, series.Format(tick, step), 10, tickColor, AnchorEnd)
+	}
+
+	for i, label := range series.Labels {
+		if label == "" {
+			continue
+		}
+		x := p.x(i)
+		p.canvas.Line(Point{x, marginTop}, Point{x, plotHeight - marginBottom}, 1, gridColor)
+		p.canvas.Text(x, plotHeight-marginBottom+22, label, 10, tickColor, AnchorMiddle)
+	}
+
+	return p
+}
+
+// x returns the center of the slot for the i-th value.
+func (p *plot) x(i int) float64 {
+	slot := float64(plotWidth-marginLeft-marginRight) / float64(p.count)
+	return marginLeft + slot*(float64(i)+0.5)
+}
+
+func (p *plot) y(value float64) float64 {
+	height := float64(plotHeight - marginTop - marginBottom)
+	return plotHeight - marginBottom - (value-p.min)/(p.max-p.min)*height
+}
+
+func (p *plot) slotWidth() float64 {
+	return float64(plotWidth-marginLeft-marginRight) / float64(p.count)
+}
+
+// LineChart draws the series as a line over a shaded area. It returns nil
+// when there is nothing to draw.
+func LineChart(series Series) *Canvas {
+	if len(series.Values) == 0 {
+		return nil
+	}
+
+	p := newPlot(series, false)
+	points := make([]Point, len(series.Values))
+	for i, value := range series.Values {
+		points[i] = Point{p.x(i), p.y(value)}
+	}
+
+	bottom := float64(plotHeight - marginBottom)
+	area := append([]Point{{points[0].X, bottom}}, points...)
+	area = append(area, Point{points[len(points)-1].X, bottom})
+	p.canvas.Polygon(area, areaColor)
+	p.canvas.Polyline(points, 2, lineColor)
+
+	return p.canvas
+}
+
+// BarChart draws one bar per value, starting at zero. It returns nil when
+// there is nothing to draw.
+func BarChart(series Series) *Canvas {
+	if len(series.Values) == 0 {
+		return nil
+	}
+
+	p := newPlot(series, true)
+	width := math.Max(1, p.slotWidth()*0.8)
+	base := p.y(math.Max(p.min, 0))
+	for i, value := range series.Values {
+		top := p.y(value)
+		p.canvas.Rect(p.x(i)-width/2, math.Min(top, base), width, math.Abs(base-top), barColor)
+	}
+
+	return p.canvas
+}
+
+// Gauge draws a half-circle dial for a 0-100 index, such as the Fear &
+// Greed index, with a needle pointing at value.
+func Gauge(value int) *Canvas {
+	value = max(0, min(100, value))
+	canvas := NewCanvas(gaugeWidth, gaugeHeight, backgroundColor)
+	center := Point{gaugeWidth / 2, gaugeHeight - 80}
+	outer, inner := 170.0, 128.0
+
+	segment := math.Pi / float64(len(gaugeColors))
+	gap := 0.015
+	for i, fill := range gaugeColors {
+		start := math.Pi + float64(i)*segment
+		canvas.Sector(center, inner, outer, start+gap, start+segment-gap, fill)
+	}
+
+	angle := math.Pi + math.Pi*float64(value)/100
+	tip := Point{center.X + outer*0.85*math.Cos(angle), center.Y + outer*0.85*math.Sin(angle)}
+	canvas.Line(center, tip, 5, needleColor)
+	canvas.Circle(center, 9, needleColor)
+
+	canvas.Text(center.X, gaugeHeight-18, strconv.Itoa(value), 42, needleColor, AnchorMiddle)
+
+	return canvas
+}
+
+// niceStep rounds a raw tick interval to 1, 2, 2.5 or 5 times a power of
+// ten.
+func niceStep(raw float64) float64 {
+	if raw <= 0 {
+		return 1
+	}
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	for _, factor := range []float64{1, 2, 2.5, 5, 10} {
+		if raw <= factor*magnitude {
+			return factor * magnitude
+		}
+	}
+	return 10 * magnitude
+}
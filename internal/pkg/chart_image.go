@@ -0,0 +1,71 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg/chart"
+	"encoding/base64"
+	"fmt"
+	"log"
+	"time"
+)
+
+// HistoricalPriceChart plots the 90-day price history, labelling every
+// tenth day with its day of the month.
+func HistoricalPriceChart(historicalData *entity.HistoricalPriceData, locale *Locale) *chart.Canvas {
+	if historicalData == nil || len(historicalData.Prices) == 0 {
+		return nil
+	}
+
+	series := chart.Series{Format: func(tick float64, step float64) string {
+		formatted := locale.Number(tick, TickDecimals(tick, step))
+		if historicalData.Quote != "" {
+			return formatted
+		}
+		return locale.Money(formatted)
+	}}
+	for i, point := range historicalData.Prices {
+		series.Values = append(series.Values, point.Price)
+		series.Labels = append(series.Labels, dayLabel(i, point.Timestamp))
+	}
+
+	return chart.LineChart(series)
+}
+
+// HistoricalVolumeChart plots the 90-day traded volume in billions of USD.
+func HistoricalVolumeChart(historicalData *entity.HistoricalPriceData, locale *Locale) *chart.Canvas {
+	if historicalData == nil || len(historicalData.Volumes) == 0 {
+		return nil
+	}
+
+	series := chart.Series{Format: func(tick float64, step float64) string { return locale.Money(locale.Number(tick, 2) + "B") }}
+	for i, point := range historicalData.Volumes {
+		series.Values = append(series.Values, point.Volume/1e9)
+		series.Labels = append(series.Labels, dayLabel(i, point.Timestamp))
+	}
+
+	return chart.BarChart(series)
+}
+
+func dayLabel(index int, timestamp int64) string {
+	if index%10 != 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d", time.UnixMilli(timestamp).Day())
+}
+
+// chartDataURI embeds the chart as a PNG data URI so the stored email body
+// stays self-contained; MailMessage turns it into an inline CID attachment
+// when the message is sent.
+func chartDataURI(canvas *chart.Canvas) string {
+	if canvas == nil {
+		return ""
+	}
+
+	image, err := canvas.PNG()
+	if err != nil {
+		log.Printf("Failed to render chart: %v", err)
+		return ""
+	}
+
+	return "data:image/png;base64," + base64.StdEncoding.EncodeToString(image)
+}
@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"strings"
+)
+
+// priceMetrics are compared against prices, which may need more than two
+// decimals.
+var priceMetrics = map[string]bool{
+	entity.MetricPrice:       true,
+	entity.MetricMinPrice90d: true,
+	entity.MetricMaxPrice90d: true,
+	entity.MetricAvgPrice90d: true,
+}
+
+func FormatCompositeEmailSubject(message AlertMessage) string {
+	locale := GetLocale(message.Locale)
+	return "🧩 " + locale.T("composite.subject", message.Symbol, DescribeCondition(locale, message.CompositeRule), locale.Price(message.Price, message.QuoteSymbol))
+}
+
+func FormatCompositeEmailBody(message AlertMessage) (EmailBody, error) {
+	return renderEmail("composite", newAlertEmailData(message))
+}
+
+// DescribeCondition renders a rule tree as a single readable line, e.g.
+// "(Variação 24h (%) < -8,00 E Fear & Greed < 25,00)".
+func DescribeCondition(locale *Locale, condition *entity.Condition) string {
+	if condition == nil {
+		return ""
+	}
+
+	if !condition.IsGroup() {
+		value := locale.Number(condition.Value, 2)
+		if priceMetrics[condition.Metric] {
+			value = locale.Number(condition.Value, PriceDecimals(condition.Value))
+		}
+		return conditionMetricLabel(locale, condition.Metric) + " " + condition.Comparator + " " + value
+	}
+
+	parts := make([]string, 0, len(condition.Conditions))
+	for i := range condition.Conditions {
+		parts = append(parts, DescribeCondition(locale, &condition.Conditions[i]))
+	}
+
+	return "(" + strings.Join(parts, " "+locale.T("condition."+condition.Operator)+" ") + ")"
+}
+
+func FormatExpressionEmailSubject(message AlertMessage) string {
+	locale := GetLocale(message.Locale)
+	return "🧮 " + locale.T("expression.subject", message.Symbol, locale.Price(message.Price, message.QuoteSymbol))
+}
+
+func FormatExpressionEmailBody(message AlertMessage) (EmailBody, error) {
+	return renderEmail("expression", newAlertEmailData(message))
+}
+
+// conditionMetricLabel names a metric in the locale, keeping unknown metrics
+// as is.
+func conditionMetricLabel(locale *Locale, metric string) string {
+	if label, ok := locale.lookup("metric." + metric); ok {
+		return label
+	}
+	return metric
+}
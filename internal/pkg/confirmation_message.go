@@ -0,0 +1,14 @@
+package pkg
+
+func FormatConfirmationEmailSubject(symbol string, locale string) string {
+	return GetLocale(locale).T("confirmation.subject", symbol)
+}
+
+func FormatConfirmationEmailBody(symbol string, confirmationURL string, expiryHours int, locale string) (EmailBody, error) {
+	return renderEmail("confirmation", struct {
+		Symbol          string
+		ConfirmationURL string
+		ExpiryHours     int
+		L               *Locale
+	}{symbol, confirmationURL, expiryHours, GetLocale(locale)})
+}
@@ -0,0 +1,18 @@
+package pkg
+
+import "math"
+
+func FormatDepegEmailSubject(message AlertMessage) string {
+	locale := GetLocale(message.Locale)
+	key := "depeg.subject.up"
+	if message.Direction == "down" {
+		key = "depeg.subject.down"
+	}
+
+	return "⚠️ " + locale.T(key, message.Symbol, locale.Money(locale.Number(message.Price, 4)),
+		locale.Number(math.Abs(message.DeviationBps), 0), locale.Money(locale.Number(message.PegPrice, 4)))
+}
+
+func FormatDepegEmailBody(message AlertMessage) (EmailBody, error) {
+	return renderEmail("depeg", newAlertEmailData(message))
+}
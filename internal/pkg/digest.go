@@ -0,0 +1,121 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"sort"
+	"time"
+)
+
+type DigestEntry struct {
+	Symbol           string
+	Name             string
+	Price            float64
+	PercentChange24h float64
+	PercentChange7d  float64
+}
+
+type Digest struct {
+	Email          string
+	Frequency      string
+	Since          *time.Time
+	Entries        []DigestEntry
+	Alerts         []*entity.AlertHistory
+	FearGreedValue int
+	FearGreedClass string
+
+	// Language of the email, taken from the subscriptions it covers
+	Locale string
+	// Time zone of the subscriber, UTC when nil
+	Location *time.Location
+}
+
+// BuildDigest summarizes the symbols watched by the given subscriptions, one
+// entry per symbol, with the alerts fired since the previous digest.
+func BuildDigest(
+	email string,
+	frequency string,
+	thresholds []*entity.AlertThreshold,
+	cryptoData map[string]*entity.CryptoCurrency,
+	alerts []*entity.AlertHistory,
+	fearGreed *entity.FearGreedIndex,
+	since *time.Time,
+	location *time.Location,
+) Digest {
+	digest := Digest{
+		Email:     email,
+		Frequency: frequency,
+		Since:     since,
+		Alerts:    alerts,
+		Location:  location,
+	}
+	if len(thresholds) > 0 {
+		digest.Locale = thresholds[0].Locale
+	}
+
+	seen := make(map[string]bool)
+	for _, threshold := range thresholds {
+		if seen[threshold.CryptoSymbol] {
+			continue
+		}
+		seen[threshold.CryptoSymbol] = true
+
+		data, exists := cryptoData[threshold.CryptoSymbol]
+		if !exists {
+			continue
+		}
+
+		digest.Entries = append(digest.Entries, DigestEntry{
+			Symbol:           threshold.CryptoSymbol,
+			Name:             data.Name,
+			Price:            data.Price,
+			PercentChange24h: data.PercentChange24h,
+			PercentChange7d:  data.PercentChange7d,
+		})
+	}
+
+	sort.Slice(digest.Entries, func(i, j int) bool {
+		return digest.Entries[i].Symbol < digest.Entries[j].Symbol
+	})
+
+	if fearGreed != nil {
+		digest.FearGreedValue = fearGreed.Value
+		digest.FearGreedClass = fearGreed.Classification
+	}
+
+	return digest
+}
+
+func FormatDigestEmailSubject(digest Digest) string {
+	key := "digest.subject.daily"
+	if digest.Frequency == entity.DigestFrequencyWeekly {
+		key = "digest.subject.weekly"
+	}
+
+	locale := GetLocale(digest.Locale)
+	if len(digest.Alerts) == 0 {
+		return locale.T(key, len(digest.Entries))
+	}
+	return locale.T(key+".alerts", len(digest.Entries), len(digest.Alerts))
+}
+
+func FormatDigestEmailBody(digest Digest) (EmailBody, error) {
+	return renderEmail("digest", struct {
+		Digest
+		L *Locale
+	}{digest, GetLocale(digest.Locale)})
+}
+
+// LocalTime converts t to the subscriber's time zone.
+func (d Digest) LocalTime(t time.Time) time.Time {
+	if d.Location == nil {
+		return t.UTC()
+	}
+	return t.In(d.Location)
+}
+
+func changeColor(value float64) string {
+	if value < 0 {
+		return "#c0392b"
+	}
+	return "#27ae60"
+}
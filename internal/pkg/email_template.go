@@ -0,0 +1,190 @@
+package pkg
+
+import (
+	"bytes"
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg/chart"
+	"embed"
+	"fmt"
+	"html/template"
+	"io"
+	"log"
+	"math"
+	"path/filepath"
+	"sync/atomic"
+	texttemplate "text/template"
+)
+
+//go:embed templates/*.html templates/*.txt
+var embeddedTemplates embed.FS
+
+var emailTemplateFuncs = template.FuncMap{
+	"abs":                  math.Abs,
+	"neg":                  func(value float64) float64 { return -value },
+	"div":                  func(value float64, divisor float64) float64 { return value / divisor },
+	"conditionMetricLabel": conditionMetricLabel,
+	"describeCondition":    DescribeCondition,
+	"changeColor":          changeColor,
+	"subject":              FormatEmailSubject,
+	"chartPeriod":          chartPeriod,
+	"priceChart": func(historicalData *entity.HistoricalPriceData, locale *Locale) template.URL {
+		return template.URL(chartDataURI(HistoricalPriceChart(historicalData, locale)))
+	},
+	"volumeChart": func(historicalData *entity.HistoricalPriceData, locale *Locale) template.URL {
+		return template.URL(chartDataURI(HistoricalVolumeChart(historicalData, locale)))
+	},
+	"fearGreedChart": func(value int) template.URL {
+		return template.URL(chartDataURI(chart.Gauge(value)))
+	},
+}
+
+// emailTextTemplateFuncs are the functions of the plain text templates,
+// which have no charts.
+var emailTextTemplateFuncs = texttemplate.FuncMap{
+	"abs":                  math.Abs,
+	"neg":                  func(value float64) float64 { return -value },
+	"div":                  func(value float64, divisor float64) float64 { return value / divisor },
+	"conditionMetricLabel": conditionMetricLabel,
+	"describeCondition":    DescribeCondition,
+	"subject":              FormatEmailSubject,
+	"chartPeriod":          chartPeriod,
+}
+
+// EmailBody is an email rendered from the same data twice: as HTML and as
+// plain text for the text/plain alternative.
+type EmailBody struct {
+	HTML string
+	Text string
+}
+
+// emailTemplates pairs every *.html template with the *.txt one of the same
+// name.
+type emailTemplates struct {
+	html *template.Template
+	text *texttemplate.Template
+}
+
+var (
+	defaultEmailTemplates = mustParseEmailTemplates()
+	activeEmailTemplates  atomic.Pointer[emailTemplates]
+)
+
+func init() {
+	activeEmailTemplates.Store(defaultEmailTemplates)
+}
+
+func mustParseEmailTemplates() *emailTemplates {
+	templates, err := parseEmailTemplates("")
+	if err != nil {
+		panic(err)
+	}
+	return templates
+}
+
+// LoadEmailTemplates overrides the embedded email templates with the *.html
+// and *.txt files in dir. A file replaces the embedded one with the same
+// name and may redefine any partial; the embedded set is used again when
+// dir is empty.
+func LoadEmailTemplates(dir string) error {
+	templates, err := parseEmailTemplates(dir)
+	if err != nil {
+		return err
+	}
+
+	activeEmailTemplates.Store(templates)
+	if dir != "" {
+		log.Printf("Email templates loaded from %s", dir)
+	}
+	return nil
+}
+
+func parseEmailTemplates(dir string) (*emailTemplates, error) {
+	htmlTemplates, err := template.New("").Funcs(emailTemplateFuncs).ParseFS(embeddedTemplates, "templates/*.html")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar templates de e-mail: %w", err)
+	}
+	textTemplates, err := texttemplate.New("").Funcs(emailTextTemplateFuncs).ParseFS(embeddedTemplates, "templates/*.txt")
+	if err != nil {
+		return nil, fmt.Errorf("erro ao carregar templates de e-mail em texto: %w", err)
+	}
+	if dir == "" {
+		return &emailTemplates{html: htmlTemplates, text: textTemplates}, nil
+	}
+
+	htmlFiles, err := filepath.Glob(filepath.Join(dir, "*.html"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar templates de e-mail em %s: %w", dir, err)
+	}
+	textFiles, err := filepath.Glob(filepath.Join(dir, "*.txt"))
+	if err != nil {
+		return nil, fmt.Errorf("erro ao listar templates de e-mail em %s: %w", dir, err)
+	}
+	if len(htmlFiles) == 0 && len(textFiles) == 0 {
+		return nil, fmt.Errorf("nenhum template de e-mail encontrado em %s", dir)
+	}
+
+	if len(htmlFiles) > 0 {
+		if htmlTemplates, err = htmlTemplates.ParseFiles(htmlFiles...); err != nil {
+			return nil, fmt.Errorf("erro ao carregar templates de e-mail de %s: %w", dir, err)
+		}
+	}
+	if len(textFiles) > 0 {
+		if textTemplates, err = textTemplates.ParseFiles(textFiles...); err != nil {
+			return nil, fmt.Errorf("erro ao carregar templates de e-mail em texto de %s: %w", dir, err)
+		}
+	}
+	return &emailTemplates{html: htmlTemplates, text: textTemplates}, nil
+}
+
+// renderEmail executes the HTML and text templates of the named email,
+// e.g. "variation" for variation.html and variation.txt. It fails only when
+// an embedded template does, so callers never send an empty email.
+func renderEmail(name string, data interface{}) (EmailBody, error) {
+	templates := activeEmailTemplates.Load()
+
+	htmlBody, err := executeEmailTemplate(templates.html, defaultEmailTemplates.html, name+".html", data)
+	if err != nil {
+		return EmailBody{}, err
+	}
+	textBody, err := executeEmailTemplate(templates.text, defaultEmailTemplates.text, name+".txt", data)
+	if err != nil {
+		return EmailBody{}, err
+	}
+
+	return EmailBody{HTML: htmlBody, Text: tidyText(textBody)}, nil
+}
+
+type templateExecutor interface {
+	ExecuteTemplate(w io.Writer, name string, data interface{}) error
+}
+
+// executeEmailTemplate falls back to the embedded template when an
+// overridden one fails so the email is still sent.
+func executeEmailTemplate(active templateExecutor, embedded templateExecutor, name string, data interface{}) (string, error) {
+	var buf bytes.Buffer
+	err := active.ExecuteTemplate(&buf, name, data)
+	if err == nil {
+		return buf.String(), nil
+	}
+	if active == embedded {
+		return "", fmt.Errorf("erro ao renderizar template de e-mail %s: %w", name, err)
+	}
+
+	log.Printf("Failed to render email template %s, using the embedded one: %v", name, err)
+	buf.Reset()
+	if err := embedded.ExecuteTemplate(&buf, name, data); err != nil {
+		return "", fmt.Errorf("erro ao renderizar template de e-mail %s: %w", name, err)
+	}
+	return buf.String(), nil
+}
+
+// alertEmailData is what the alert templates receive: the message fields
+// plus L, the locale used for text and number formatting.
+type alertEmailData struct {
+	AlertMessage
+	L *Locale
+}
+
+func newAlertEmailData(message AlertMessage) alertEmailData {
+	return alertEmailData{AlertMessage: message, L: GetLocale(message.Locale)}
+}
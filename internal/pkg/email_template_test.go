@@ -0,0 +1,279 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+var update = flag.Bool("update", false, "rewrite the golden files in testdata")
+
+var goldenActions = []ActionLink{
+	{Action: entity.AlertActionSnooze1h, URL: "https://alerts.example.com/actions?token=snooze"},
+	{Action: entity.AlertActionUnsubscribeSymbol, Symbol: "BTC", URL: "https://alerts.example.com/actions?token=unsubscribe"},
+}
+
+// goldenEmails renders every email template with fixed data. Charts and the
+// Fear & Greed gauge are left out to keep the golden files readable.
+func goldenEmails() map[string]func() (string, EmailBody, error) {
+	alert := func(message AlertMessage) func() (string, EmailBody, error) {
+		return func() (string, EmailBody, error) {
+			body, err := FormatEmailBody(message)
+			return FormatEmailSubject(message), body, err
+		}
+	}
+
+	variation := AlertMessage{
+		Symbol:    "BTC",
+		Name:      "Bitcoin",
+		Price:     65432.1,
+		Volume:    28_500_000_000,
+		Period:    "24h",
+		Variation: 5.25,
+		Threshold: 5,
+		Direction: entity.DirectionUp,
+		Actions:   goldenActions,
+	}
+	targetPrice := AlertMessage{
+		Symbol:        "ETH",
+		Name:          "Ethereum",
+		Price:         2990.5,
+		Volume:        12_300_000_000,
+		IsTargetPrice: true,
+		TargetPrice:   3000,
+		Direction:     entity.DirectionDown,
+		Locale:        LocaleEnglish,
+		Actions:       goldenActions,
+	}
+	digest := func(locale string, frequency string, location *time.Location) func() (string, EmailBody, error) {
+		return func() (string, EmailBody, error) {
+			since := time.Date(2026, 3, 13, 9, 0, 0, 0, time.UTC)
+			digest := Digest{
+				Email:     "user@example.com",
+				Frequency: frequency,
+				Locale:    locale,
+				Location:  location,
+				Since:     &since,
+				Entries: []DigestEntry{
+					{Symbol: "BTC", Name: "Bitcoin", Price: 65432.1, PercentChange24h: 2.5, PercentChange7d: -1.25},
+					{Symbol: "PEPE", Name: "Pepe", Price: 0.00001234, PercentChange24h: -7.8, PercentChange7d: 12},
+				},
+				Alerts: []*entity.AlertHistory{
+					{Symbol: "BTC", Subject: "🟢 BTC subiu 5,25% em 24h: Preço atual US$ 65.432,10", CreatedAt: since.Add(3 * time.Hour)},
+				},
+			}
+			body, err := FormatDigestEmailBody(digest)
+			return FormatDigestEmailSubject(digest), body, err
+		}
+	}
+	confirmation := func(symbol string, locale string) func() (string, EmailBody, error) {
+		return func() (string, EmailBody, error) {
+			body, err := FormatConfirmationEmailBody(symbol, "https://alerts.example.com/confirm?token=abc", 48, locale)
+			return FormatConfirmationEmailSubject(symbol, locale), body, err
+		}
+	}
+	queuedSummary := func(locale string) func() (string, EmailBody, error) {
+		return func() (string, EmailBody, error) {
+			queuedAt := time.Date(2026, 3, 14, 2, 30, 0, 0, time.UTC)
+			alerts := []*entity.QueuedAlert{
+				{Subject: "🟢 BTC subiu 5,25% em 24h: Preço atual US$ 65.432,10", Locale: locale, CreatedAt: queuedAt},
+				{Subject: "🎯 Preço Alvo: ETH caiu abaixo de US$ 3.000,00 (atual: US$ 2.990,50)", Locale: locale, CreatedAt: queuedAt.Add(45 * time.Minute)},
+			}
+			body, err := FormatQueuedSummaryEmailBody(alerts)
+			return FormatQueuedSummaryEmailSubject(alerts), body, err
+		}
+	}
+
+	return map[string]func() (string, EmailBody, error){
+		"variation":    alert(variation),
+		"target_price": alert(targetPrice),
+		"depeg": alert(AlertMessage{
+			Symbol:         "USDC",
+			Name:           "USD Coin",
+			Price:          0.9951,
+			Volume:         5_100_000_000,
+			IsDepeg:        true,
+			PegPrice:       1,
+			DeviationBps:   -49,
+			BandBps:        30,
+			SustainedScans: 3,
+			Direction:      entity.DirectionDown,
+		}),
+		"range": alert(AlertMessage{
+			Symbol:            "SOL",
+			Name:              "Solana",
+			Price:             142.37,
+			PreviousPrice:     145.1,
+			Volume:            3_200_000_000,
+			Threshold:         10,
+			IsRange:           true,
+			RangeKind:         RangeKindDrawdown,
+			RangeLookbackDays: 90,
+			RangeHigh:         180.2,
+			RangeLow:          120.05,
+			DrawdownPercent:   -20.99,
+			Locale:            LocaleSpanish,
+		}),
+		"volatility": alert(AlertMessage{
+			Symbol:             "BTC",
+			Name:               "Bitcoin",
+			Price:              65432.1,
+			Volume:             28_500_000_000,
+			Threshold:          2,
+			IsVolatility:       true,
+			ShortWindow:        7,
+			LongWindow:         30,
+			ShortVolatility:    84.2,
+			LongVolatility:     38.7,
+			ShortRangePercent:  4.1,
+			LongRangePercent:   2.05,
+			VolatilityMultiple: 2.18,
+			Locale:             LocaleEnglish,
+		}),
+		"composite": alert(AlertMessage{
+			Symbol:      "BTC",
+			Name:        "Bitcoin",
+			Price:       58000,
+			Volume:      31_000_000_000,
+			IsComposite: true,
+			CompositeRule: &entity.Condition{
+				Operator: entity.ConditionOperatorAnd,
+				Conditions: []entity.Condition{
+					{Metric: entity.MetricPctChange24h, Comparator: "<", Value: -8},
+					{Metric: entity.MetricFearGreedIndex, Comparator: "<", Value: 25},
+				},
+			},
+			ConditionResults: []entity.ConditionResult{
+				{Metric: entity.MetricPctChange24h, Comparator: "<", Value: -8, Actual: -9.4, Available: true, Matched: true},
+				{Metric: entity.MetricFearGreedIndex, Comparator: "<", Value: 25, Available: false},
+			},
+			Actions: goldenActions,
+		}),
+		"expression": alert(AlertMessage{
+			Symbol:       "BTC",
+			Name:         "Bitcoin",
+			Price:        58000,
+			Volume:       31_000_000_000,
+			IsExpression: true,
+			Expression:   "pct_change_24h < -5 && price < avg_price_90d",
+			ExpressionValues: map[string]float64{
+				entity.MetricPrice:        58000,
+				entity.MetricPctChange24h: -6.2,
+				entity.MetricAvgPrice90d:  61250.5,
+			},
+		}),
+		"pnl": alert(AlertMessage{
+			Symbol:        "ETH",
+			Name:          "Ethereum",
+			Price:         3500,
+			IsPnL:         true,
+			PnLKind:       PnLKindAmount,
+			Threshold:     1000,
+			Direction:     entity.DirectionUp,
+			Quantity:      1.5,
+			EntryPrice:    2700,
+			PositionValue: 5250,
+			CostBasis:     4050,
+			PnLAmount:     1200,
+			PnLPercent:    29.63,
+		}),
+		"portfolio": alert(AlertMessage{
+			IsPortfolio:    true,
+			Threshold:      100_000,
+			Direction:      entity.DirectionDown,
+			PortfolioValue: 95_250,
+			PortfolioCost:  80_000,
+			Positions: []entity.Position{
+				{Holding: entity.Holding{CryptoSymbol: "BTC", Quantity: 1.2}, Price: 65000, Value: 78000, PnLAmount: 18000, PnLPercent: 30},
+				{Holding: entity.Holding{CryptoSymbol: "ETH", Quantity: 5}, Price: 3450, Value: 17250, PnLAmount: -2750, PnLPercent: -13.75},
+			},
+			Locale: LocaleEnglish,
+		}),
+		"batch": func() (string, EmailBody, error) {
+			messages := []AlertMessage{variation, {
+				Symbol:        "BTC",
+				Name:          "Bitcoin",
+				Price:         65432.1,
+				Volume:        28_500_000_000,
+				IsTargetPrice: true,
+				TargetPrice:   65000,
+				Direction:     entity.DirectionUp,
+			}}
+			body, err := FormatBatchEmailBody(messages)
+			return FormatBatchEmailSubject(messages), body, err
+		},
+		"digest":            digest(DefaultLocale, entity.DigestFrequencyDaily, time.FixedZone("BRT", -3*60*60)),
+		"digest_en":         digest(LocaleEnglish, entity.DigestFrequencyWeekly, time.FixedZone("EST", -5*60*60)),
+		"digest_es":         digest(LocaleSpanish, entity.DigestFrequencyDaily, nil),
+		"confirmation":      confirmation("BTC", DefaultLocale),
+		"confirmation_en":   confirmation("ETH", LocaleEnglish),
+		"queued_summary":    queuedSummary(DefaultLocale),
+		"queued_summary_en": queuedSummary(LocaleEnglish),
+		"queued_summary_es": queuedSummary(LocaleSpanish),
+	}
+}
+
+// TestEmailTemplatesGolden diffs every rendered email against its file in
+// testdata. Run with -update to rewrite them after changing a template.
+func TestEmailTemplatesGolden(t *testing.T) {
+	templates, err := embeddedTemplates.ReadDir("templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	emails := goldenEmails()
+	for _, entry := range templates {
+		name := strings.TrimSuffix(strings.TrimSuffix(entry.Name(), ".html"), ".txt")
+		if name != "partials" && emails[name] == nil {
+			t.Errorf("template %s has no golden test", entry.Name())
+		}
+	}
+
+	for name, render := range emails {
+		t.Run(name, func(t *testing.T) {
+			subject, body, err := render()
+			if err != nil {
+				t.Fatal(err)
+			}
+			checkGolden(t, name+".golden", "Subject: "+subject+"\n\n"+body.HTML+"\n")
+			checkGolden(t, name+".txt.golden", "Subject: "+subject+"\n\n"+body.Text)
+		})
+	}
+}
+
+func checkGolden(t *testing.T, file string, got string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", file)
+	if *update {
+		if err := os.WriteFile(path, []byte(got), 0o644); err != nil {
+			t.Fatal(err)
+		}
+		return
+	}
+
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("%v (run with -update to create it)", err)
+	}
+	if got != string(want) {
+		t.Errorf("%s differs from the rendered email (run with -update if intended)\n--- got\n%s\n--- want\n%s", path, got, want)
+	}
+}
+
+func TestRenderEmailReportsFailures(t *testing.T) {
+	body, err := renderEmail("missing", nil)
+	if err == nil || body != (EmailBody{}) {
+		t.Errorf("renderEmail of a missing template = %q, %v, want an error", body, err)
+	}
+
+	// Data lacking the fields a template uses must not yield a partial email
+	body, err = renderEmail("variation", struct{ L *Locale }{})
+	if err == nil || body != (EmailBody{}) {
+		t.Errorf("renderEmail with broken data = %q, %v, want an error", body, err)
+	}
+}
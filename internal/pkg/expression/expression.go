@@ -0,0 +1,174 @@
+// Package expression implements the small condition language used by custom
+// alerts, e.g. "pct_change_24h < -5 && volume_24h > 2 * avg_volume_90d".
+//
+// The language only knows numbers, booleans, a fixed set of variables and a
+// few pure functions (abs, min, max). There are no assignments, loops or
+// access to anything outside the variables passed to Eval, and expressions
+// are bounded in length and nesting depth.
+package expression
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+const (
+	maxLength = 512
+	maxDepth  = 32
+)
+
+type Program struct {
+	source    string
+	root      *node
+	variables []string
+}
+
+// Compile parses and type-checks source. Only identifiers present in
+// variables are accepted and the whole expression must be boolean.
+func Compile(source string, variables map[string]bool) (*Program, error) {
+	if source == "" {
+		return nil, fmt.Errorf("expression is empty")
+	}
+	if len(source) > maxLength {
+		return nil, fmt.Errorf("expression is longer than %d characters", maxLength)
+	}
+
+	tokens, err := tokenize(source)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens, variables: variables, used: make(map[string]bool)}
+	root, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if t := p.peek(); t.kind != tokenEOF {
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+	if root.typ != typeBool {
+		return nil, fmt.Errorf("expression must evaluate to a boolean, got %s", root.typ)
+	}
+
+	used := make([]string, 0, len(p.used))
+	for name := range p.used {
+		used = append(used, name)
+	}
+	sort.Strings(used)
+
+	return &Program{source: source, root: root, variables: used}, nil
+}
+
+func (p *Program) String() string {
+	return p.source
+}
+
+// Variables returns the variables referenced by the expression, sorted.
+func (p *Program) Variables() []string {
+	return p.variables
+}
+
+// Eval evaluates the expression. It fails when a referenced variable is
+// missing from values or on division by zero.
+func (p *Program) Eval(values map[string]float64) (bool, error) {
+	result, err := eval(p.root, values)
+	if err != nil {
+		return false, err
+	}
+	return result != 0, nil
+}
+
+func eval(n *node, values map[string]float64) (float64, error) {
+	switch n.kind {
+	case "number":
+		return n.value, nil
+
+	case "variable":
+		value, ok := values[n.name]
+		if !ok {
+			return 0, fmt.Errorf("variable %s is not available", n.name)
+		}
+		return value, nil
+
+	case "call":
+		args := make([]float64, len(n.children))
+		for i, child := range n.children {
+			value, err := eval(child, values)
+			if err != nil {
+				return 0, err
+			}
+			args[i] = value
+		}
+		return functions[n.name].call(args), nil
+
+	case "unary":
+		operand, err := eval(n.children[0], values)
+		if err != nil {
+			return 0, err
+		}
+		if n.op == "!" {
+			return boolToFloat(operand == 0), nil
+		}
+		return -operand, nil
+	}
+
+	left, err := eval(n.children[0], values)
+	if err != nil {
+		return 0, err
+	}
+
+	// Short-circuit so the unevaluated side may reference missing variables.
+	switch n.op {
+	case "&&":
+		if left == 0 {
+			return 0, nil
+		}
+	case "||":
+		if left != 0 {
+			return 1, nil
+		}
+	}
+
+	right, err := eval(n.children[1], values)
+	if err != nil {
+		return 0, err
+	}
+
+	switch n.op {
+	case "&&", "||":
+		return boolToFloat(right != 0), nil
+	case "+":
+		return left + right, nil
+	case "-":
+		return left - right, nil
+	case "*":
+		return left * right, nil
+	case "/":
+		if right == 0 {
+			return 0, fmt.Errorf("division by zero")
+		}
+		return left / right, nil
+	case "<":
+		return boolToFloat(left < right), nil
+	case "<=":
+		return boolToFloat(left <= right), nil
+	case ">":
+		return boolToFloat(left > right), nil
+	case ">=":
+		return boolToFloat(left >= right), nil
+	case "==":
+		return boolToFloat(math.Abs(left-right) < 1e-9), nil
+	case "!=":
+		return boolToFloat(math.Abs(left-right) >= 1e-9), nil
+	}
+
+	return 0, fmt.Errorf("unsupported operator %s", n.op)
+}
+
+func boolToFloat(value bool) float64 {
+	if value {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,151 @@
+package expression
+
+import (
+	"strings"
+	"testing"
+)
+
+var testVariables = map[string]bool{"x": true, "y": true, "z": true}
+
+func TestEval(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		values map[string]float64
+		want   bool
+	}{
+		{"multiplication before addition", "1 + 2 * 3 == 7", nil, true},
+		{"parentheses override precedence", "(1 + 2) * 3 == 9", nil, true},
+		{"subtraction is left associative", "10 - 4 - 3 == 3", nil, true},
+		{"division is left associative", "8 / 4 / 2 == 1", nil, true},
+		{"unary minus binds tighter than multiplication", "-2 * -3 == 6", nil, true},
+		{"double negation", "--x == x", map[string]float64{"x": 4}, true},
+		{"comparison before logical operators", "x + 1 > 2 && x * 2 < 10", map[string]float64{"x": 3}, true},
+		{"and binds tighter than or", "1 < 2 || 1 > 2 && 1 > 2", nil, true},
+		{"and binds tighter than or on the left", "1 > 2 && 1 > 2 || 1 < 2", nil, true},
+		{"not applies to the parenthesized comparison", "!(x > 1) && x < 2", map[string]float64{"x": 0}, true},
+		{"not of a true comparison", "!(x > 1)", map[string]float64{"x": 5}, false},
+		{"abs", "abs(x) == 3", map[string]float64{"x": -3}, true},
+		{"min and max", "min(x, y) == 1 && max(x, y) == 2", map[string]float64{"x": 2, "y": 1}, true},
+		{"nested calls", "max(abs(x), min(y, z)) == 5", map[string]float64{"x": -5, "y": 1, "z": 2}, true},
+		{"equality tolerates rounding", "0.1 + 0.2 == 0.3", nil, true},
+		{"inequality", "x != y", map[string]float64{"x": 1, "y": 1}, false},
+		{"and short-circuits on false", "x > 1 && y > 0", map[string]float64{"x": 0}, false},
+		{"or short-circuits on true", "x < 1 || y > 0", map[string]float64{"x": 0}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.source, testVariables)
+			if err != nil {
+				t.Fatalf("Compile(%q): %v", tt.source, err)
+			}
+			got, err := program.Eval(tt.values)
+			if err != nil {
+				t.Fatalf("Eval(%q): %v", tt.source, err)
+			}
+			if got != tt.want {
+				t.Errorf("Eval(%q) = %v, want %v", tt.source, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEvalErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		values  map[string]float64
+		wantErr string
+	}{
+		{"division by a zero literal", "x / 0 > 1", map[string]float64{"x": 1}, "division by zero"},
+		{"division by a zero expression", "x / (y - y) > 0", map[string]float64{"x": 1, "y": 2}, "division by zero"},
+		{"missing variable", "x > 0", nil, "variable x is not available"},
+		{"and evaluates the right side when the left is true", "x > -1 && y > 0", map[string]float64{"x": 0}, "variable y is not available"},
+		{"or evaluates the right side when the left is false", "x > 1 || y > 0", map[string]float64{"x": 0}, "variable y is not available"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			program, err := Compile(tt.source, testVariables)
+			if err != nil {
+				t.Fatalf("Compile(%q): %v", tt.source, err)
+			}
+			if _, err := program.Eval(tt.values); err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Eval(%q) error = %v, want %q", tt.source, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		source  string
+		wantErr string
+	}{
+		{"empty", "", "expression is empty"},
+		{"too long", "x > 0" + strings.Repeat(" ", maxLength-4), "expression is longer than 512 characters"},
+		{"nested too deep", strings.Repeat("-", maxDepth) + "x > 0", "expression is nested deeper than 32 levels"},
+		{"parentheses nested too deep", strings.Repeat("(", 16) + "x" + strings.Repeat(")", 16) + " > 0", "expression is nested deeper than 32 levels"},
+
+		{"unknown variable", "x > 1 && foo < 2", `unknown variable "foo" at position 9`},
+		{"unknown function", "sqrt(x) > 1", `unknown function "sqrt" at position 0`},
+		{"abs with two arguments", "abs(x, y) > 0", "function abs expects 1 arguments, got 2"},
+		{"min with one argument", "min(x) > 0", "function min expects 2 arguments, got 1"},
+		{"max without arguments", "max() > 0", "function max expects 2 arguments, got 0"},
+		{"max with three arguments", "max(x, y, z) > 0", "function max expects 2 arguments, got 3"},
+
+		{"true is not a literal", "1 && true", `unknown variable "true" at position 5`},
+		{"and with a numeric operand", "1 && x > 0", "operator && at position 2 requires boolean operands"},
+		{"or with a numeric operand", "x > 0 || y", "operator || at position 6 requires boolean operands"},
+		{"arithmetic on a boolean", "x + (y > 1) > 0", "operator + at position 2 requires numeric operands"},
+		{"comparison of booleans", "(x > 1) == (y > 1)", "operator == at position 8 requires numeric operands"},
+		{"not of a number", "!x", "operator ! at position 0 requires a boolean operand"},
+		{"minus of a boolean", "-(x > 1)", "operator - at position 0 requires a numeric operand"},
+		{"function of a boolean", "abs(x > 1) > 0", "function abs at position 0 requires numeric arguments"},
+		{"numeric result", "x + 1", "expression must evaluate to a boolean, got number"},
+
+		{"unexpected operator", "x > > 1", `unexpected ">" at position 4`},
+		{"trailing token", "x > 1 1", `unexpected "1" at position 6`},
+		{"unclosed parenthesis", "(x > 1", "expected ) at position 6"},
+		{"unclosed call", "abs(x", "expected ) at position 5"},
+		{"unexpected character", "x > 1 @ 2", `unexpected character '@' at position 6`},
+		{"invalid number", "x > 1..2", `invalid number "1..2" at position 4`},
+		{"unexpected end", "x >", "unexpected end of expression"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := Compile(tt.source, testVariables)
+			if err == nil || err.Error() != tt.wantErr {
+				t.Errorf("Compile(%q) error = %v, want %q", tt.source, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCompileLimits(t *testing.T) {
+	source := "x > 0" + strings.Repeat(" ", maxLength-5)
+	if _, err := Compile(source, testVariables); err != nil {
+		t.Errorf("expression of exactly %d characters: %v", maxLength, err)
+	}
+
+	source = strings.Repeat("-", maxDepth-1) + "x > 0"
+	if _, err := Compile(source, testVariables); err != nil {
+		t.Errorf("expression nested %d levels: %v", maxDepth, err)
+	}
+}
+
+func TestVariables(t *testing.T) {
+	program, err := Compile("z > 1 && (x < y || abs(x) > z)", testVariables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := strings.Join(program.Variables(), ","); got != "x,y,z" {
+		t.Errorf("Variables() = %s, want x,y,z", got)
+	}
+	if got := program.String(); got != "z > 1 && (x < y || abs(x) > z)" {
+		t.Errorf("String() = %q", got)
+	}
+}
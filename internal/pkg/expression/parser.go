@@ -0,0 +1,291 @@
+package expression
+
+import "fmt"
+
+type valueType int
+
+const (
+	typeNumber valueType = iota
+	typeBool
+)
+
+func (t valueType) String() string {
+	if t == typeBool {
+		return "boolean"
+	}
+	return "number"
+}
+
+type node struct {
+	kind     string
+	op       string
+	name     string
+	value    float64
+	children []*node
+	typ      valueType
+}
+
+type function struct {
+	arity int
+	call  func(args []float64) float64
+}
+
+var functions = map[string]function{
+	"abs": {arity: 1, call: func(args []float64) float64 {
+		if args[0] < 0 {
+			return -args[0]
+		}
+		return args[0]
+	}},
+	"min": {arity: 2, call: func(args []float64) float64 {
+		if args[0] < args[1] {
+			return args[0]
+		}
+		return args[1]
+	}},
+	"max": {arity: 2, call: func(args []float64) float64 {
+		if args[0] > args[1] {
+			return args[0]
+		}
+		return args[1]
+	}},
+}
+
+// parser is a recursive descent parser with one function per precedence
+// level, from || (lowest) down to unary operators and primaries.
+type parser struct {
+	tokens    []token
+	pos       int
+	depth     int
+	variables map[string]bool
+	used      map[string]bool
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	t := p.tokens[p.pos]
+	if t.kind != tokenEOF {
+		p.pos++
+	}
+	return t
+}
+
+func (p *parser) acceptOperator(ops ...string) (string, bool) {
+	t := p.peek()
+	if t.kind != tokenOperator {
+		return "", false
+	}
+	for _, op := range ops {
+		if t.text == op {
+			p.next()
+			return op, true
+		}
+	}
+	return "", false
+}
+
+func (p *parser) enter() error {
+	p.depth++
+	if p.depth > maxDepth {
+		return fmt.Errorf("expression is nested deeper than %d levels", maxDepth)
+	}
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) parseOr() (*node, error) {
+	return p.parseLogical(p.parseAnd, "||")
+}
+
+func (p *parser) parseAnd() (*node, error) {
+	return p.parseLogical(p.parseComparison, "&&")
+}
+
+func (p *parser) parseLogical(operand func() (*node, error), op string) (*node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		if _, ok := p.acceptOperator(op); !ok {
+			return left, nil
+		}
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if left.typ != typeBool || right.typ != typeBool {
+			return nil, fmt.Errorf("operator %s at position %d requires boolean operands", op, t.pos)
+		}
+
+		left = &node{kind: "binary", op: op, children: []*node{left, right}, typ: typeBool}
+	}
+}
+
+func (p *parser) parseComparison() (*node, error) {
+	left, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+
+	t := p.peek()
+	op, ok := p.acceptOperator("<", "<=", ">", ">=", "==", "!=")
+	if !ok {
+		return left, nil
+	}
+
+	right, err := p.parseAdditive()
+	if err != nil {
+		return nil, err
+	}
+	if left.typ != typeNumber || right.typ != typeNumber {
+		return nil, fmt.Errorf("operator %s at position %d requires numeric operands", op, t.pos)
+	}
+
+	return &node{kind: "binary", op: op, children: []*node{left, right}, typ: typeBool}, nil
+}
+
+func (p *parser) parseAdditive() (*node, error) {
+	return p.parseArithmetic(p.parseMultiplicative, "+", "-")
+}
+
+func (p *parser) parseMultiplicative() (*node, error) {
+	return p.parseArithmetic(p.parseUnary, "*", "/")
+}
+
+func (p *parser) parseArithmetic(operand func() (*node, error), ops ...string) (*node, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		t := p.peek()
+		op, ok := p.acceptOperator(ops...)
+		if !ok {
+			return left, nil
+		}
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+		if left.typ != typeNumber || right.typ != typeNumber {
+			return nil, fmt.Errorf("operator %s at position %d requires numeric operands", op, t.pos)
+		}
+
+		left = &node{kind: "binary", op: op, children: []*node{left, right}, typ: typeNumber}
+	}
+}
+
+func (p *parser) parseUnary() (*node, error) {
+	if err := p.enter(); err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	t := p.peek()
+	op, ok := p.acceptOperator("!", "-")
+	if !ok {
+		return p.parsePrimary()
+	}
+
+	operand, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	if op == "!" && operand.typ != typeBool {
+		return nil, fmt.Errorf("operator ! at position %d requires a boolean operand", t.pos)
+	}
+	if op == "-" && operand.typ != typeNumber {
+		return nil, fmt.Errorf("operator - at position %d requires a numeric operand", t.pos)
+	}
+
+	return &node{kind: "unary", op: op, children: []*node{operand}, typ: operand.typ}, nil
+}
+
+func (p *parser) parsePrimary() (*node, error) {
+	t := p.next()
+
+	switch t.kind {
+	case tokenNumber:
+		return &node{kind: "number", value: t.value, typ: typeNumber}, nil
+
+	case tokenIdent:
+		if p.peek().kind == tokenLParen {
+			return p.parseCall(t)
+		}
+		if !p.variables[t.text] {
+			return nil, fmt.Errorf("unknown variable %q at position %d", t.text, t.pos)
+		}
+		p.used[t.text] = true
+		return &node{kind: "variable", name: t.text, typ: typeNumber}, nil
+
+	case tokenLParen:
+		if err := p.enter(); err != nil {
+			return nil, err
+		}
+		defer p.leave()
+
+		inner, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if closing := p.next(); closing.kind != tokenRParen {
+			return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+		}
+		return inner, nil
+
+	case tokenEOF:
+		return nil, fmt.Errorf("unexpected end of expression")
+
+	default:
+		return nil, fmt.Errorf("unexpected %q at position %d", t.text, t.pos)
+	}
+}
+
+func (p *parser) parseCall(name token) (*node, error) {
+	fn, ok := functions[name.text]
+	if !ok {
+		return nil, fmt.Errorf("unknown function %q at position %d", name.text, name.pos)
+	}
+	p.next()
+
+	var args []*node
+	if p.peek().kind != tokenRParen {
+		for {
+			arg, err := p.parseOr()
+			if err != nil {
+				return nil, err
+			}
+			if arg.typ != typeNumber {
+				return nil, fmt.Errorf("function %s at position %d requires numeric arguments", name.text, name.pos)
+			}
+			args = append(args, arg)
+
+			if p.peek().kind != tokenComma {
+				break
+			}
+			p.next()
+		}
+	}
+
+	if closing := p.next(); closing.kind != tokenRParen {
+		return nil, fmt.Errorf("expected ) at position %d", closing.pos)
+	}
+	if len(args) != fn.arity {
+		return nil, fmt.Errorf("function %s expects %d arguments, got %d", name.text, fn.arity, len(args))
+	}
+
+	return &node{kind: "call", name: name.text, children: args, typ: typeNumber}, nil
+}
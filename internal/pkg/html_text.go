@@ -0,0 +1,52 @@
+package pkg
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+var (
+	htmlBlockedRegex   = regexp.MustCompile(`(?is)<(style|script)[^>]*>.*?</(style|script)>`)
+	htmlLinkRegex      = regexp.MustCompile(`(?is)<a\s[^>]*href=['"]([^'"]*)['"][^>]*>(.*?)</a>`)
+	htmlImageRegex     = regexp.MustCompile(`(?is)<img\s[^>]*?alt=['"]([^'"]*)['"][^>]*>`)
+	htmlListItemRegex  = regexp.MustCompile(`(?i)<li[^>]*>`)
+	htmlLineBreakRegex = regexp.MustCompile(`(?i)<br\s*/?>|</tr>`)
+	htmlBlockEndRegex  = regexp.MustCompile(`(?i)</(p|h[1-6]|ul|ol|table|div)>|<hr\s*/?>`)
+	htmlCellEndRegex   = regexp.MustCompile(`(?i)</(td|th)>`)
+	htmlTagRegex       = regexp.MustCompile(`(?s)<[^>]*>`)
+	blankLinesRegex    = regexp.MustCompile(`\n{3,}`)
+)
+
+// HTMLToText renders the HTML emails built in this package as plain text
+// for the text/plain alternative: links keep their URL, list items become
+// dashes and images are replaced by their alt text.
+func HTMLToText(body string) string {
+	text := htmlBlockedRegex.ReplaceAllString(body, "")
+	text = htmlLinkRegex.ReplaceAllString(text, "$2 ($1)")
+	text = htmlImageRegex.ReplaceAllString(text, "[$1]")
+	text = htmlListItemRegex.ReplaceAllString(text, "\n- ")
+	text = htmlLineBreakRegex.ReplaceAllString(text, "\n")
+	text = htmlBlockEndRegex.ReplaceAllString(text, "\n\n")
+	text = htmlCellEndRegex.ReplaceAllString(text, "  ")
+	text = htmlTagRegex.ReplaceAllString(text, "")
+	text = html.UnescapeString(text)
+
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	return tidyText(strings.Join(lines, "\n"))
+}
+
+// tidyText drops trailing spaces and runs of blank lines, such as those left
+// by template blocks that rendered nothing.
+func tidyText(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimRight(line, " \t")
+	}
+	text = blankLinesRegex.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+
+	return strings.TrimSpace(text) + "\n"
+}
@@ -0,0 +1,283 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"fmt"
+	"html/template"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	LocalePortuguese = "pt-BR"
+	LocaleEnglish    = "en"
+	LocaleSpanish    = "es"
+
+	// Used for subscriptions without a locale and for messages a catalog
+	// does not translate
+	DefaultLocale = LocalePortuguese
+)
+
+// Locale holds the messages of one language together with its number,
+// currency and date conventions.
+type Locale struct {
+	Tag string
+
+	decimalSeparator   string
+	thousandsSeparator string
+	// fmt layout wrapping USD amounts, e.g. "US$ %s"
+	currencyLayout  string
+	dateLayout      string
+	shortDateLayout string
+	timeLayout      string
+
+	messages map[string]string
+}
+
+var locales = map[string]*Locale{
+	LocalePortuguese: {
+		Tag:                LocalePortuguese,
+		decimalSeparator:   ",",
+		thousandsSeparator: ".",
+		currencyLayout:     "US$ %s",
+		dateLayout:         "02/01/2006",
+		shortDateLayout:    "02/01",
+		timeLayout:         "15:04",
+		messages:           portugueseMessages,
+	},
+	LocaleEnglish: {
+		Tag:                LocaleEnglish,
+		decimalSeparator:   ".",
+		thousandsSeparator: ",",
+		currencyLayout:     "$%s",
+		dateLayout:         "Jan 2, 2006",
+		shortDateLayout:    "Jan 2",
+		timeLayout:         "3:04 PM",
+		messages:           englishMessages,
+	},
+	LocaleSpanish: {
+		Tag:                LocaleSpanish,
+		decimalSeparator:   ",",
+		thousandsSeparator: ".",
+		currencyLayout:     "US$ %s",
+		dateLayout:         "02/01/2006",
+		shortDateLayout:    "02/01",
+		timeLayout:         "15:04",
+		messages:           spanishMessages,
+	},
+}
+
+// NormalizeLocale maps tags such as "pt_br", "en-US" or "ES" onto a
+// supported locale. It returns an empty string when the language is not
+// supported.
+func NormalizeLocale(tag string) string {
+	tag = strings.ToLower(strings.ReplaceAll(strings.TrimSpace(tag), "_", "-"))
+	language, _, _ := strings.Cut(tag, "-")
+
+	switch language {
+	case "pt":
+		return LocalePortuguese
+	case "en":
+		return LocaleEnglish
+	case "es":
+		return LocaleSpanish
+	}
+	return ""
+}
+
+// PreferredLocale returns the first supported language of an
+// Accept-Language header, or an empty string when there is none.
+func PreferredLocale(acceptLanguage string) string {
+	for _, tag := range strings.Split(acceptLanguage, ",") {
+		tag, _, _ = strings.Cut(tag, ";")
+		if locale := NormalizeLocale(tag); locale != "" {
+			return locale
+		}
+	}
+	return ""
+}
+
+// GetLocale returns the catalog for tag, falling back to DefaultLocale.
+func GetLocale(tag string) *Locale {
+	if locale, ok := locales[NormalizeLocale(tag)]; ok {
+		return locale
+	}
+	return locales[DefaultLocale]
+}
+
+// T formats the message registered under key, taken from the default
+// locale when this one lacks it.
+func (l *Locale) T(key string, args ...interface{}) string {
+	format, ok := l.lookup(key)
+	if !ok {
+		return key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// HTML formats a message that contains markup, such as <strong>. The
+// message itself is trusted while string arguments are escaped.
+func (l *Locale) HTML(key string, args ...interface{}) template.HTML {
+	escaped := make([]interface{}, len(args))
+	for i, arg := range args {
+		if text, ok := arg.(string); ok {
+			arg = template.HTMLEscapeString(text)
+		}
+		escaped[i] = arg
+	}
+	return template.HTML(l.T(key, escaped...))
+}
+
+// Text formats a message for plain text emails, dropping its markup and
+// turning line breaks into newlines.
+func (l *Locale) Text(key string, args ...interface{}) string {
+	format, ok := l.lookup(key)
+	if !ok {
+		return key
+	}
+	format = htmlLineBreakRegex.ReplaceAllString(format, "\n")
+	format = htmlTagRegex.ReplaceAllString(format, "")
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+func (l *Locale) lookup(key string) (string, bool) {
+	if format, ok := l.messages[key]; ok {
+		return format, true
+	}
+	format, ok := locales[DefaultLocale].messages[key]
+	return format, ok
+}
+
+// Number prints value with the given decimals and the locale's separators.
+func (l *Locale) Number(value float64, decimals int) string {
+	formatted := strconv.FormatFloat(math.Abs(value), 'f', decimals, 64)
+	integer, fraction, _ := strings.Cut(formatted, ".")
+
+	var grouped strings.Builder
+	if value < 0 && strings.Trim(formatted, "0.") != "" {
+		grouped.WriteString("-")
+	}
+	for i, digit := range integer {
+		if i > 0 && (len(integer)-i)%3 == 0 {
+			grouped.WriteString(l.thousandsSeparator)
+		}
+		grouped.WriteRune(digit)
+	}
+	if fraction != "" {
+		grouped.WriteString(l.decimalSeparator + fraction)
+	}
+	return grouped.String()
+}
+
+func (l *Locale) Percent(value float64) string {
+	return l.Number(value, 2) + "%"
+}
+
+// SignedPercent always shows the sign, e.g. "+3,50%".
+func (l *Locale) SignedPercent(value float64) string {
+	if value >= 0 {
+		return "+" + l.Percent(value)
+	}
+	return l.Percent(value)
+}
+
+// Quantity prints an asset amount with up to eight decimals, dropping
+// trailing zeros.
+func (l *Locale) Quantity(value float64) string {
+	formatted := l.Number(value, 8)
+	if strings.Contains(formatted, l.decimalSeparator) {
+		formatted = strings.TrimRight(strings.TrimRight(formatted, "0"), l.decimalSeparator)
+	}
+	return formatted
+}
+
+// Money wraps an already formatted USD amount in the locale's currency
+// notation.
+func (l *Locale) Money(amount string) string {
+	return fmt.Sprintf(l.currencyLayout, amount)
+}
+
+// SignedMoney prints an abbreviated USD result with its sign, e.g.
+// "-US$ 1,2K".
+func (l *Locale) SignedMoney(value float64) string {
+	if value < 0 {
+		return "-" + l.Money(l.LargeNumber(-value))
+	}
+	return "+" + l.Money(l.LargeNumber(value))
+}
+
+// Billions prints a USD volume in billions, e.g. "US$ 23,40B".
+func (l *Locale) Billions(value float64) string {
+	return l.Money(l.Number(value/1e9, 2) + "B")
+}
+
+// Price prints USD prices with the precision given by PriceDecimals and pair
+// ratios in units of the quote asset, e.g. "0,03512 BTC".
+func (l *Locale) Price(value float64, quote string) string {
+	if quote == "" {
+		return l.Money(l.Number(value, PriceDecimals(value)))
+	}
+	return l.Number(value, PriceDecimals(value)) + " " + quote
+}
+
+// LargeNumber abbreviates value with K, M, B or T.
+func (l *Locale) LargeNumber(value float64) string {
+	abs := math.Abs(value)
+	sign := ""
+	if value < 0 {
+		sign = "-"
+	}
+
+	switch {
+	case abs >= 1e12:
+		return sign + l.Number(abs/1e12, 1) + "T"
+	case abs >= 1e9:
+		return sign + l.Number(abs/1e9, 1) + "B"
+	case abs >= 1e6:
+		return sign + l.Number(abs/1e6, 1) + "M"
+	case abs >= 1e3:
+		return sign + l.Number(abs/1e3, 1) + "K"
+	default:
+		return sign + l.Number(abs, 2)
+	}
+}
+
+func (l *Locale) Date(t time.Time) string {
+	return t.Format(l.dateLayout)
+}
+
+// DateTime prints the date and time of t followed by its time zone, which
+// is left to the caller to set.
+func (l *Locale) DateTime(t time.Time) string {
+	return t.Format(l.dateLayout + " " + l.timeLayout + " MST")
+}
+
+// ShortDateTime prints day, month and time, for times close to the date
+// shown elsewhere in the email.
+func (l *Locale) ShortDateTime(t time.Time) string {
+	return t.Format(l.shortDateLayout + " " + l.timeLayout)
+}
+
+// FearGreedClass translates the classification reported by the Fear &
+// Greed index, keeping it as is when unknown.
+func (l *Locale) FearGreedClass(classification string) string {
+	if translated, ok := l.lookup("feargreed." + strings.ToLower(classification)); ok {
+		return translated
+	}
+	return classification
+}
+
+func (l *Locale) ActionLabel(link ActionLink) string {
+	if link.Action == entity.AlertActionUnsubscribeSymbol {
+		return l.T("action."+link.Action, link.Symbol)
+	}
+	return l.T("action." + link.Action)
+}
@@ -0,0 +1,559 @@
+package pkg
+
+// Message catalogs keyed by message ID. Keys missing from a catalog fall
+// back to portugueseMessages.
+
+var portugueseMessages = map[string]string{
+	"greeting":               "Olá,",
+	"footer.regards":         "Atenciosamente,<br/>Equipe Crypto Alerts",
+	"footer.noreply":         "Este é um e-mail automático. Por favor, não responda.",
+	"details.current":        "Detalhes atuais:",
+	"details.market":         "Detalhes atuais do mercado:",
+	"details.price":          "Preço Atual",
+	"details.previous_price": "Preço na verificação anterior",
+	"details.volume":         "Volume negociado nas últimas 24h",
+	"details.variation":      "Variação no período (%s)",
+
+	"variation.subject.up":   "%s subiu %s em %s: Preço atual %s",
+	"variation.subject.down": "%s caiu %s em %s: Preço atual %s",
+	"variation.intro":        "Temos um alerta de preço para a criptomoeda <strong>%s (%s)</strong>!",
+	"variation.crossed.up":   "A variação no período de %s subiu acima do seu alerta configurado de %s, atingindo <strong>%s</strong>.",
+	"variation.crossed.down": "A variação no período de %s caiu abaixo do seu alerta configurado de %s, atingindo <strong>%s</strong>.",
+	"variation.closing":      "Este é um bom momento para verificar seus investimentos e decidir os próximos passos.",
+
+	"target.subject.up":      "Preço Alvo: %s ultrapassou %s (atual: %s)",
+	"target.subject.down":    "Preço Alvo: %s caiu abaixo de %s (atual: %s)",
+	"target.triggered":       "Seu alerta de preço alvo foi acionado!",
+	"target.crossed.up":      "A criptomoeda <strong>%s (%s)</strong> cruzou para cima seu preço alvo configurado de <strong>%s</strong>.",
+	"target.crossed.down":    "A criptomoeda <strong>%s (%s)</strong> cruzou para baixo seu preço alvo configurado de <strong>%s</strong>.",
+	"target.suggestion.up":   "Este pode ser um bom momento para considerar vender, dependendo da sua estratégia.",
+	"target.suggestion.down": "Este pode ser um bom momento para considerar comprar, dependendo da sua estratégia.",
+
+	"batch.subject": "%d alertas disparados: %s",
+	"batch.intro":   "%d alertas foram disparados nesta verificação:",
+
+	"depeg.subject.up":     "Depeg: %s a %s, %s bps acima da paridade de %s",
+	"depeg.subject.down":   "Depeg: %s a %s, %s bps abaixo da paridade de %s",
+	"depeg.intro":          "A stablecoin <strong>%s (%s)</strong> está fora da paridade!",
+	"depeg.deviation.up":   "O preço atual de <strong>%s</strong> está <strong>%s bps (%s) acima</strong> da paridade de %s, fora da banda configurada de %s bps por <strong>%d verificações consecutivas</strong>.",
+	"depeg.deviation.down": "O preço atual de <strong>%s</strong> está <strong>%s bps (%s) abaixo</strong> da paridade de %s, fora da banda configurada de %s bps por <strong>%d verificações consecutivas</strong>.",
+	"depeg.peg":            "Paridade",
+	"depeg.deviation":      "Desvio",
+	"depeg.band":           "banda",
+	"depeg.closing":        "Desvios sustentados da paridade podem indicar problemas de liquidez ou de lastro. Avalie sua exposição a esta stablecoin.",
+
+	"range.subject.new_high": "%s atingiu nova máxima de %d dias: %s",
+	"range.subject.new_low":  "%s atingiu nova mínima de %d dias: %s",
+	"range.subject.drawdown": "%s está %s abaixo da máxima de %d dias: %s",
+	"range.intro":            "Temos um alerta de faixa histórica para a criptomoeda <strong>%s (%s)</strong>!",
+	"range.new_high":         "O preço superou a máxima anterior de %d dias de <strong>%s</strong>.",
+	"range.new_low":          "O preço caiu abaixo da mínima anterior de %d dias de <strong>%s</strong>.",
+	"range.drawdown":         "O preço está <strong>%s</strong> abaixo da máxima de %d dias, ultrapassando o recuo configurado de %s.",
+	"range.title":            "Faixa dos últimos %d dias:",
+	"range.high":             "Máxima",
+	"range.low":              "Mínima",
+	"range.distance":         "Distância da máxima",
+
+	"volatility.subject":     "%s: volatilidade de %dd em %sx a média de %dd (%s vs %s anualizada)",
+	"volatility.intro":       "A criptomoeda <strong>%s (%s)</strong> entrou em um regime de volatilidade elevada!",
+	"volatility.regime":      "A volatilidade realizada dos últimos %d dias está <strong>%sx</strong> acima da referência de %d dias, superando o múltiplo configurado de %sx.",
+	"volatility.title":       "Volatilidade:",
+	"volatility.realized":    "Volatilidade realizada (%dd, anualizada)",
+	"volatility.daily_range": "Movimento diário médio (%dd)",
+	"volatility.closing":     "Períodos de volatilidade elevada costumam trazer movimentos bruscos. Revise o tamanho das suas posições e ordens de proteção.",
+
+	"composite.subject":     "%s: regra composta acionada (%s) - Preço atual %s",
+	"composite.intro":       "Sua regra composta para a criptomoeda <strong>%s (%s)</strong> foi acionada!",
+	"composite.rule":        "Regra configurada:",
+	"composite.evaluation":  "Avaliação das condições:",
+	"composite.actual":      "atual",
+	"composite.unavailable": "indisponível",
+	"condition.and":         "E",
+	"condition.or":          "OU",
+
+	"expression.subject": "%s: expressão personalizada acionada - Preço atual %s",
+	"expression.intro":   "Sua expressão personalizada para a criptomoeda <strong>%s (%s)</strong> foi acionada!",
+	"expression.label":   "Expressão:",
+	"expression.values":  "Valores utilizados:",
+
+	"metric.price":                "Preço",
+	"metric.volume_24h":           "Volume 24h",
+	"metric.volume_change_24h":    "Variação do volume 24h (%)",
+	"metric.market_cap":           "Market cap",
+	"metric.market_cap_dominance": "Dominância (%)",
+	"metric.pct_change_1h":        "Variação 1h (%)",
+	"metric.pct_change_24h":       "Variação 24h (%)",
+	"metric.pct_change_7d":        "Variação 7d (%)",
+	"metric.pct_change_30d":       "Variação 30d (%)",
+	"metric.pct_change_60d":       "Variação 60d (%)",
+	"metric.pct_change_90d":       "Variação 90d (%)",
+	"metric.min_price_90d":        "Preço mínimo 90d",
+	"metric.max_price_90d":        "Preço máximo 90d",
+	"metric.avg_price_90d":        "Preço médio 90d",
+	"metric.min_volume_90d":       "Volume mínimo 90d",
+	"metric.max_volume_90d":       "Volume máximo 90d",
+	"metric.avg_volume_90d":       "Volume médio 90d",
+	"metric.fear_greed":           "Fear & Greed",
+
+	"pnl.subject.up":   "Sua posição em %s atingiu lucro de %s (%s)",
+	"pnl.subject.down": "Sua posição em %s atingiu prejuízo de %s (%s)",
+	"pnl.crossed":      "O resultado não realizado da sua posição em <strong>%s (%s)</strong> cruzou o limite configurado de <strong>%s</strong>.",
+	"pnl.position":     "Sua posição:",
+	"pnl.quantity":     "Quantidade",
+	"pnl.entry_price":  "Preço médio de entrada",
+	"pnl.cost":         "Custo total",
+	"pnl.value":        "Valor atual da posição",
+	"pnl.result":       "Resultado não realizado",
+
+	"portfolio.subject.up":   "Seu portfólio ultrapassou %s (atual: %s)",
+	"portfolio.subject.down": "Seu portfólio caiu abaixo de %s (atual: %s)",
+	"portfolio.crossed.up":   "O valor total do seu portfólio cruzou para cima o limite configurado de <strong>%s</strong>.",
+	"portfolio.crossed.down": "O valor total do seu portfólio cruzou para baixo o limite configurado de <strong>%s</strong>.",
+	"portfolio.summary":      "Resumo:",
+	"portfolio.total":        "Valor total",
+	"portfolio.positions":    "Posições:",
+	"portfolio.asset":        "Ativo",
+	"portfolio.price":        "Preço",
+	"portfolio.value":        "Valor",
+	"portfolio.result":       "Resultado",
+
+	"digest.subject.daily":         "📊 Resumo diário: %d moedas acompanhadas",
+	"digest.subject.weekly":        "📊 Resumo semanal: %d moedas acompanhadas",
+	"digest.subject.daily.alerts":  "📊 Resumo diário: %d moedas acompanhadas, %d alertas disparados",
+	"digest.subject.weekly.alerts": "📊 Resumo semanal: %d moedas acompanhadas, %d alertas disparados",
+	"digest.intro":                 "Este é o resumo das criptomoedas que você acompanha.",
+	"digest.market":                "Mercado:",
+	"digest.coin":                  "Moeda",
+	"digest.price":                 "Preço",
+	"digest.alerts":                "Alertas disparados:",
+	"digest.alerts.since":          "Alertas disparados desde %s:",
+	"digest.no_alerts":             "Nenhum alerta foi disparado no período.",
+	"queued.subject":               "🌙 %d alertas recebidos durante seu horário de silêncio",
+	"queued.intro":                 "Estes alertas foram disparados durante o seu horário de silêncio:",
+	"queued.outdated":              "Os preços podem ter mudado desde então. Confira o mercado antes de tomar qualquer decisão.",
+	"confirmation.subject":         "✉️ Confirme seu alerta de %s",
+	"confirmation.request":         "Recebemos um pedido para enviar alertas de <strong>%s</strong> para este endereço.",
+	"confirmation.link":            "Clique aqui para confirmar seu e-mail",
+	"confirmation.activate":        "e ativar os alertas.",
+	"confirmation.open":            "Abra o link abaixo para confirmar seu e-mail e ativar os alertas:",
+	"confirmation.expiry":          "O link é válido por %d horas. Se você não fez este pedido, ignore esta mensagem e nenhum alerta será enviado.",
+
+	"chart.price.title":  "Histórico de Preço (90 dias)",
+	"chart.price.alt":    "Gráfico do histórico de preço",
+	"chart.volume.title": "Volume Negociado (90 dias)",
+	"chart.volume.alt":   "Gráfico do volume negociado",
+	"chart.stats":        "Mín: %s | Máx: %s | Média: %s",
+	"chart.period":       "De %s a %s",
+
+	"feargreed.title":         "Índice Fear & Greed do Mercado",
+	"feargreed.alt":           "Índice Fear & Greed",
+	"feargreed.extreme fear":  "Medo extremo",
+	"feargreed.fear":          "Medo",
+	"feargreed.neutral":       "Neutro",
+	"feargreed.greed":         "Ganância",
+	"feargreed.extreme greed": "Ganância extrema",
+
+	"actions.manage":            "Gerenciar este alerta:",
+	"action.snooze_1h":          "Silenciar por 1h",
+	"action.snooze_24h":         "Silenciar por 24h",
+	"action.disable_rule":       "Desativar esta condição",
+	"action.unsubscribe_symbol": "Cancelar alertas de %s",
+
+	"page.team":                      "Equipe Crypto Alerts",
+	"page.invalid.title":             "Link inválido",
+	"page.invalid":                   "Este link é inválido ou expirou.",
+	"page.error.title":               "Erro",
+	"page.error":                     "Não foi possível concluir a ação. Tente novamente mais tarde.",
+	"page.action.title":              "Confirmar ação",
+	"page.action.button":             "Confirmar",
+	"page.action.question":           "Deseja %s?",
+	"page.action.done.title":         "Pronto!",
+	"page.action.done":               "Sua solicitação para %s foi concluída.",
+	"page.action.not_found.title":    "Alerta não encontrado",
+	"page.action.not_found":          "Este alerta não existe mais.",
+	"page.action.snooze_1h":          "silenciar este alerta de %s por 1 hora",
+	"page.action.snooze_24h":         "silenciar este alerta de %s por 24 horas",
+	"page.action.disable_rule":       "desativar esta condição do alerta de %s",
+	"page.action.unsubscribe_symbol": "cancelar todos os seus alertas de %s",
+	"page.unsubscribe.title":         "Cancelar inscrição",
+	"page.unsubscribe.button":        "Cancelar inscrição",
+	"page.unsubscribe.question":      "Deseja parar de receber todos os e-mails do Crypto Alerts em <strong>%s</strong>?",
+	"page.unsubscribe.done.title":    "Inscrição cancelada",
+	"page.unsubscribe.done":          "<strong>%s</strong> não receberá mais e-mails do Crypto Alerts.",
+	"page.confirm.title":             "Confirmar e-mail",
+	"page.confirm.button":            "Confirmar e-mail",
+	"page.confirm.question":          "Confirme que deseja receber alertas em <strong>%s</strong>.",
+	"page.confirm.invalid":           "Este link é inválido ou expirou. Crie o alerta novamente para receber um novo link.",
+	"page.confirm.done.title":        "E-mail confirmado",
+	"page.confirm.done":              "Obrigado! %d alertas foram ativados.",
+}
+
+var englishMessages = map[string]string{
+	"greeting":               "Hello,",
+	"footer.regards":         "Best regards,<br/>The Crypto Alerts Team",
+	"footer.noreply":         "This is an automated email. Please do not reply.",
+	"details.current":        "Current details:",
+	"details.market":         "Current market details:",
+	"details.price":          "Current price",
+	"details.previous_price": "Price at the previous check",
+	"details.volume":         "24h trading volume",
+	"details.variation":      "Change over the period (%s)",
+
+	"variation.subject.up":   "%s rose %s in %s: current price %s",
+	"variation.subject.down": "%s fell %s in %s: current price %s",
+	"variation.intro":        "We have a price alert for <strong>%s (%s)</strong>!",
+	"variation.crossed.up":   "The %s change rose above your configured alert of %s, reaching <strong>%s</strong>.",
+	"variation.crossed.down": "The %s change fell below your configured alert of %s, reaching <strong>%s</strong>.",
+	"variation.closing":      "This is a good time to review your investments and decide on your next steps.",
+
+	"target.subject.up":      "Target price: %s rose above %s (current: %s)",
+	"target.subject.down":    "Target price: %s fell below %s (current: %s)",
+	"target.triggered":       "Your target price alert was triggered!",
+	"target.crossed.up":      "<strong>%s (%s)</strong> crossed above your configured target price of <strong>%s</strong>.",
+	"target.crossed.down":    "<strong>%s (%s)</strong> crossed below your configured target price of <strong>%s</strong>.",
+	"target.suggestion.up":   "This may be a good time to consider selling, depending on your strategy.",
+	"target.suggestion.down": "This may be a good time to consider buying, depending on your strategy.",
+
+	"batch.subject": "%d alerts triggered: %s",
+	"batch.intro":   "%d alerts were triggered in this check:",
+
+	"depeg.subject.up":     "Depeg: %s at %s, %s bps above the %s peg",
+	"depeg.subject.down":   "Depeg: %s at %s, %s bps below the %s peg",
+	"depeg.intro":          "The stablecoin <strong>%s (%s)</strong> is off its peg!",
+	"depeg.deviation.up":   "The current price of <strong>%s</strong> is <strong>%s bps (%s) above</strong> the %s peg, outside your configured band of %s bps for <strong>%d consecutive checks</strong>.",
+	"depeg.deviation.down": "The current price of <strong>%s</strong> is <strong>%s bps (%s) below</strong> the %s peg, outside your configured band of %s bps for <strong>%d consecutive checks</strong>.",
+	"depeg.peg":            "Peg",
+	"depeg.deviation":      "Deviation",
+	"depeg.band":           "band",
+	"depeg.closing":        "Sustained deviations from the peg may signal liquidity or backing issues. Review your exposure to this stablecoin.",
+
+	"range.subject.new_high": "%s hit a new %d-day high: %s",
+	"range.subject.new_low":  "%s hit a new %d-day low: %s",
+	"range.subject.drawdown": "%s is %s below its %d-day high: %s",
+	"range.intro":            "We have a historical range alert for <strong>%s (%s)</strong>!",
+	"range.new_high":         "The price rose above the previous %d-day high of <strong>%s</strong>.",
+	"range.new_low":          "The price fell below the previous %d-day low of <strong>%s</strong>.",
+	"range.drawdown":         "The price is <strong>%s</strong> below its %d-day high, past your configured drawdown of %s.",
+	"range.title":            "Range over the last %d days:",
+	"range.high":             "High",
+	"range.low":              "Low",
+	"range.distance":         "Distance from the high",
+
+	"volatility.subject":     "%s: %dd volatility at %sx the %dd average (%s vs %s annualized)",
+	"volatility.intro":       "<strong>%s (%s)</strong> entered a high volatility regime!",
+	"volatility.regime":      "Realized volatility over the last %d days is <strong>%sx</strong> the %d-day baseline, above your configured multiple of %sx.",
+	"volatility.title":       "Volatility:",
+	"volatility.realized":    "Realized volatility (%dd, annualized)",
+	"volatility.daily_range": "Average daily move (%dd)",
+	"volatility.closing":     "High volatility periods often bring sharp moves. Review your position sizes and protective orders.",
+
+	"composite.subject":     "%s: composite rule triggered (%s) - current price %s",
+	"composite.intro":       "Your composite rule for <strong>%s (%s)</strong> was triggered!",
+	"composite.rule":        "Configured rule:",
+	"composite.evaluation":  "Condition results:",
+	"composite.actual":      "current",
+	"composite.unavailable": "unavailable",
+	"condition.and":         "AND",
+	"condition.or":          "OR",
+
+	"expression.subject": "%s: custom expression triggered - current price %s",
+	"expression.intro":   "Your custom expression for <strong>%s (%s)</strong> was triggered!",
+	"expression.label":   "Expression:",
+	"expression.values":  "Values used:",
+
+	"metric.price":                "Price",
+	"metric.volume_24h":           "24h volume",
+	"metric.volume_change_24h":    "24h volume change (%)",
+	"metric.market_cap":           "Market cap",
+	"metric.market_cap_dominance": "Dominance (%)",
+	"metric.pct_change_1h":        "1h change (%)",
+	"metric.pct_change_24h":       "24h change (%)",
+	"metric.pct_change_7d":        "7d change (%)",
+	"metric.pct_change_30d":       "30d change (%)",
+	"metric.pct_change_60d":       "60d change (%)",
+	"metric.pct_change_90d":       "90d change (%)",
+	"metric.min_price_90d":        "90d minimum price",
+	"metric.max_price_90d":        "90d maximum price",
+	"metric.avg_price_90d":        "90d average price",
+	"metric.min_volume_90d":       "90d minimum volume",
+	"metric.max_volume_90d":       "90d maximum volume",
+	"metric.avg_volume_90d":       "90d average volume",
+	"metric.fear_greed":           "Fear & Greed",
+
+	"pnl.subject.up":   "Your %s position reached a profit of %s (%s)",
+	"pnl.subject.down": "Your %s position reached a loss of %s (%s)",
+	"pnl.crossed":      "The unrealized result of your <strong>%s (%s)</strong> position crossed your configured limit of <strong>%s</strong>.",
+	"pnl.position":     "Your position:",
+	"pnl.quantity":     "Quantity",
+	"pnl.entry_price":  "Average entry price",
+	"pnl.cost":         "Total cost",
+	"pnl.value":        "Current position value",
+	"pnl.result":       "Unrealized result",
+
+	"portfolio.subject.up":   "Your portfolio rose above %s (current: %s)",
+	"portfolio.subject.down": "Your portfolio fell below %s (current: %s)",
+	"portfolio.crossed.up":   "The total value of your portfolio crossed above your configured limit of <strong>%s</strong>.",
+	"portfolio.crossed.down": "The total value of your portfolio crossed below your configured limit of <strong>%s</strong>.",
+	"portfolio.summary":      "Summary:",
+	"portfolio.total":        "Total value",
+	"portfolio.positions":    "Positions:",
+	"portfolio.asset":        "Asset",
+	"portfolio.price":        "Price",
+	"portfolio.value":        "Value",
+	"portfolio.result":       "Result",
+
+	"digest.subject.daily":         "📊 Daily summary: %d coins watched",
+	"digest.subject.weekly":        "📊 Weekly summary: %d coins watched",
+	"digest.subject.daily.alerts":  "📊 Daily summary: %d coins watched, %d alerts fired",
+	"digest.subject.weekly.alerts": "📊 Weekly summary: %d coins watched, %d alerts fired",
+	"digest.intro":                 "Here is the summary of the cryptocurrencies you follow.",
+	"digest.market":                "Market:",
+	"digest.coin":                  "Coin",
+	"digest.price":                 "Price",
+	"digest.alerts":                "Alerts fired:",
+	"digest.alerts.since":          "Alerts fired since %s:",
+	"digest.no_alerts":             "No alerts were fired in this period.",
+	"queued.subject":               "🌙 %d alerts received during your quiet hours",
+	"queued.intro":                 "These alerts were fired during your quiet hours:",
+	"queued.outdated":              "Prices may have changed since then. Check the market before making any decision.",
+	"confirmation.subject":         "✉️ Confirm your %s alert",
+	"confirmation.request":         "We received a request to send <strong>%s</strong> alerts to this address.",
+	"confirmation.link":            "Click here to confirm your email",
+	"confirmation.activate":        "and activate the alerts.",
+	"confirmation.open":            "Open the link below to confirm your email and activate the alerts:",
+	"confirmation.expiry":          "The link is valid for %d hours. If you did not make this request, ignore this message and no alerts will be sent.",
+
+	"chart.price.title":  "Price History (90 days)",
+	"chart.price.alt":    "Price history chart",
+	"chart.volume.title": "Trading Volume (90 days)",
+	"chart.volume.alt":   "Trading volume chart",
+	"chart.stats":        "Min: %s | Max: %s | Avg: %s",
+	"chart.period":       "From %s to %s",
+
+	"feargreed.title":         "Market Fear & Greed Index",
+	"feargreed.alt":           "Fear & Greed Index",
+	"feargreed.extreme fear":  "Extreme fear",
+	"feargreed.fear":          "Fear",
+	"feargreed.neutral":       "Neutral",
+	"feargreed.greed":         "Greed",
+	"feargreed.extreme greed": "Extreme greed",
+
+	"actions.manage":            "Manage this alert:",
+	"action.snooze_1h":          "Snooze for 1h",
+	"action.snooze_24h":         "Snooze for 24h",
+	"action.disable_rule":       "Disable this condition",
+	"action.unsubscribe_symbol": "Stop %s alerts",
+
+	"page.team":                      "The Crypto Alerts Team",
+	"page.invalid.title":             "Invalid link",
+	"page.invalid":                   "This link is invalid or has expired.",
+	"page.error.title":               "Error",
+	"page.error":                     "The action could not be completed. Please try again later.",
+	"page.action.title":              "Confirm action",
+	"page.action.button":             "Confirm",
+	"page.action.question":           "Do you want to %s?",
+	"page.action.done.title":         "Done!",
+	"page.action.done":               "Your request to %s was completed.",
+	"page.action.not_found.title":    "Alert not found",
+	"page.action.not_found":          "This alert no longer exists.",
+	"page.action.snooze_1h":          "snooze this %s alert for 1 hour",
+	"page.action.snooze_24h":         "snooze this %s alert for 24 hours",
+	"page.action.disable_rule":       "disable this condition of the %s alert",
+	"page.action.unsubscribe_symbol": "cancel all your %s alerts",
+	"page.unsubscribe.title":         "Unsubscribe",
+	"page.unsubscribe.button":        "Unsubscribe",
+	"page.unsubscribe.question":      "Do you want to stop receiving all Crypto Alerts emails at <strong>%s</strong>?",
+	"page.unsubscribe.done.title":    "Unsubscribed",
+	"page.unsubscribe.done":          "<strong>%s</strong> will no longer receive Crypto Alerts emails.",
+	"page.confirm.title":             "Confirm email",
+	"page.confirm.button":            "Confirm email",
+	"page.confirm.question":          "Confirm that you want to receive alerts at <strong>%s</strong>.",
+	"page.confirm.invalid":           "This link is invalid or has expired. Create the alert again to receive a new link.",
+	"page.confirm.done.title":        "Email confirmed",
+	"page.confirm.done":              "Thank you! %d alerts were activated.",
+}
+
+var spanishMessages = map[string]string{
+	"greeting":               "Hola,",
+	"footer.regards":         "Saludos cordiales,<br/>Equipo Crypto Alerts",
+	"footer.noreply":         "Este es un correo automático. Por favor, no responda.",
+	"details.current":        "Detalles actuales:",
+	"details.market":         "Detalles actuales del mercado:",
+	"details.price":          "Precio actual",
+	"details.previous_price": "Precio en la verificación anterior",
+	"details.volume":         "Volumen negociado en las últimas 24h",
+	"details.variation":      "Variación en el período (%s)",
+
+	"variation.subject.up":   "%s subió %s en %s: precio actual %s",
+	"variation.subject.down": "%s bajó %s en %s: precio actual %s",
+	"variation.intro":        "¡Tenemos una alerta de precio para la criptomoneda <strong>%s (%s)</strong>!",
+	"variation.crossed.up":   "La variación en el período de %s superó su alerta configurada de %s, alcanzando <strong>%s</strong>.",
+	"variation.crossed.down": "La variación en el período de %s bajó de su alerta configurada de %s, alcanzando <strong>%s</strong>.",
+	"variation.closing":      "Este es un buen momento para revisar sus inversiones y decidir los próximos pasos.",
+
+	"target.subject.up":      "Precio objetivo: %s superó %s (actual: %s)",
+	"target.subject.down":    "Precio objetivo: %s cayó por debajo de %s (actual: %s)",
+	"target.triggered":       "¡Su alerta de precio objetivo se activó!",
+	"target.crossed.up":      "La criptomoneda <strong>%s (%s)</strong> cruzó hacia arriba su precio objetivo configurado de <strong>%s</strong>.",
+	"target.crossed.down":    "La criptomoneda <strong>%s (%s)</strong> cruzó hacia abajo su precio objetivo configurado de <strong>%s</strong>.",
+	"target.suggestion.up":   "Este puede ser un buen momento para considerar vender, según su estrategia.",
+	"target.suggestion.down": "Este puede ser un buen momento para considerar comprar, según su estrategia.",
+
+	"batch.subject": "%d alertas activadas: %s",
+	"batch.intro":   "Se activaron %d alertas en esta verificación:",
+
+	"depeg.subject.up":     "Depeg: %s a %s, %s bps por encima de la paridad de %s",
+	"depeg.subject.down":   "Depeg: %s a %s, %s bps por debajo de la paridad de %s",
+	"depeg.intro":          "¡La stablecoin <strong>%s (%s)</strong> perdió la paridad!",
+	"depeg.deviation.up":   "El precio actual de <strong>%s</strong> está <strong>%s bps (%s) por encima</strong> de la paridad de %s, fuera de la banda configurada de %s bps durante <strong>%d verificaciones consecutivas</strong>.",
+	"depeg.deviation.down": "El precio actual de <strong>%s</strong> está <strong>%s bps (%s) por debajo</strong> de la paridad de %s, fuera de la banda configurada de %s bps durante <strong>%d verificaciones consecutivas</strong>.",
+	"depeg.peg":            "Paridad",
+	"depeg.deviation":      "Desviación",
+	"depeg.band":           "banda",
+	"depeg.closing":        "Las desviaciones sostenidas de la paridad pueden indicar problemas de liquidez o de respaldo. Evalúe su exposición a esta stablecoin.",
+
+	"range.subject.new_high": "%s alcanzó un nuevo máximo de %d días: %s",
+	"range.subject.new_low":  "%s alcanzó un nuevo mínimo de %d días: %s",
+	"range.subject.drawdown": "%s está %s por debajo del máximo de %d días: %s",
+	"range.intro":            "¡Tenemos una alerta de rango histórico para la criptomoneda <strong>%s (%s)</strong>!",
+	"range.new_high":         "El precio superó el máximo anterior de %d días de <strong>%s</strong>.",
+	"range.new_low":          "El precio cayó por debajo del mínimo anterior de %d días de <strong>%s</strong>.",
+	"range.drawdown":         "El precio está <strong>%s</strong> por debajo del máximo de %d días, superando la caída configurada de %s.",
+	"range.title":            "Rango de los últimos %d días:",
+	"range.high":             "Máximo",
+	"range.low":              "Mínimo",
+	"range.distance":         "Distancia del máximo",
+
+	"volatility.subject":     "%s: volatilidad de %dd en %sx la media de %dd (%s vs %s anualizada)",
+	"volatility.intro":       "¡La criptomoneda <strong>%s (%s)</strong> entró en un régimen de volatilidad elevada!",
+	"volatility.regime":      "La volatilidad realizada de los últimos %d días está <strong>%sx</strong> por encima de la referencia de %d días, superando el múltiplo configurado de %sx.",
+	"volatility.title":       "Volatilidad:",
+	"volatility.realized":    "Volatilidad realizada (%dd, anualizada)",
+	"volatility.daily_range": "Movimiento diario medio (%dd)",
+	"volatility.closing":     "Los períodos de volatilidad elevada suelen traer movimientos bruscos. Revise el tamaño de sus posiciones y sus órdenes de protección.",
+
+	"composite.subject":     "%s: regla compuesta activada (%s) - precio actual %s",
+	"composite.intro":       "¡Su regla compuesta para la criptomoneda <strong>%s (%s)</strong> se activó!",
+	"composite.rule":        "Regla configurada:",
+	"composite.evaluation":  "Evaluación de las condiciones:",
+	"composite.actual":      "actual",
+	"composite.unavailable": "no disponible",
+	"condition.and":         "Y",
+	"condition.or":          "O",
+
+	"expression.subject": "%s: expresión personalizada activada - precio actual %s",
+	"expression.intro":   "¡Su expresión personalizada para la criptomoneda <strong>%s (%s)</strong> se activó!",
+	"expression.label":   "Expresión:",
+	"expression.values":  "Valores utilizados:",
+
+	"metric.price":                "Precio",
+	"metric.volume_24h":           "Volumen 24h",
+	"metric.volume_change_24h":    "Variación del volumen 24h (%)",
+	"metric.market_cap":           "Capitalización de mercado",
+	"metric.market_cap_dominance": "Dominancia (%)",
+	"metric.pct_change_1h":        "Variación 1h (%)",
+	"metric.pct_change_24h":       "Variación 24h (%)",
+	"metric.pct_change_7d":        "Variación 7d (%)",
+	"metric.pct_change_30d":       "Variación 30d (%)",
+	"metric.pct_change_60d":       "Variación 60d (%)",
+	"metric.pct_change_90d":       "Variación 90d (%)",
+	"metric.min_price_90d":        "Precio mínimo 90d",
+	"metric.max_price_90d":        "Precio máximo 90d",
+	"metric.avg_price_90d":        "Precio medio 90d",
+	"metric.min_volume_90d":       "Volumen mínimo 90d",
+	"metric.max_volume_90d":       "Volumen máximo 90d",
+	"metric.avg_volume_90d":       "Volumen medio 90d",
+	"metric.fear_greed":           "Fear & Greed",
+
+	"pnl.subject.up":   "Su posición en %s alcanzó una ganancia de %s (%s)",
+	"pnl.subject.down": "Su posición en %s alcanzó una pérdida de %s (%s)",
+	"pnl.crossed":      "El resultado no realizado de su posición en <strong>%s (%s)</strong> cruzó el límite configurado de <strong>%s</strong>.",
+	"pnl.position":     "Su posición:",
+	"pnl.quantity":     "Cantidad",
+	"pnl.entry_price":  "Precio medio de entrada",
+	"pnl.cost":         "Costo total",
+	"pnl.value":        "Valor actual de la posición",
+	"pnl.result":       "Resultado no realizado",
+
+	"portfolio.subject.up":   "Su portafolio superó %s (actual: %s)",
+	"portfolio.subject.down": "Su portafolio cayó por debajo de %s (actual: %s)",
+	"portfolio.crossed.up":   "El valor total de su portafolio cruzó hacia arriba el límite configurado de <strong>%s</strong>.",
+	"portfolio.crossed.down": "El valor total de su portafolio cruzó hacia abajo el límite configurado de <strong>%s</strong>.",
+	"portfolio.summary":      "Resumen:",
+	"portfolio.total":        "Valor total",
+	"portfolio.positions":    "Posiciones:",
+	"portfolio.asset":        "Activo",
+	"portfolio.price":        "Precio",
+	"portfolio.value":        "Valor",
+	"portfolio.result":       "Resultado",
+
+	"digest.subject.daily":         "📊 Resumen diario: %d monedas seguidas",
+	"digest.subject.weekly":        "📊 Resumen semanal: %d monedas seguidas",
+	"digest.subject.daily.alerts":  "📊 Resumen diario: %d monedas seguidas, %d alertas disparadas",
+	"digest.subject.weekly.alerts": "📊 Resumen semanal: %d monedas seguidas, %d alertas disparadas",
+	"digest.intro":                 "Este es el resumen de las criptomonedas que usted sigue.",
+	"digest.market":                "Mercado:",
+	"digest.coin":                  "Moneda",
+	"digest.price":                 "Precio",
+	"digest.alerts":                "Alertas disparadas:",
+	"digest.alerts.since":          "Alertas disparadas desde el %s:",
+	"digest.no_alerts":             "No se disparó ninguna alerta en el período.",
+	"queued.subject":               "🌙 %d alertas recibidas durante su horario de silencio",
+	"queued.intro":                 "Estas alertas se dispararon durante su horario de silencio:",
+	"queued.outdated":              "Los precios pueden haber cambiado desde entonces. Consulte el mercado antes de tomar cualquier decisión.",
+	"confirmation.subject":         "✉️ Confirme su alerta de %s",
+	"confirmation.request":         "Recibimos una solicitud para enviar alertas de <strong>%s</strong> a esta dirección.",
+	"confirmation.link":            "Haga clic aquí para confirmar su correo",
+	"confirmation.activate":        "y activar las alertas.",
+	"confirmation.open":            "Abra el enlace de abajo para confirmar su correo y activar las alertas:",
+	"confirmation.expiry":          "El enlace es válido durante %d horas. Si usted no hizo esta solicitud, ignore este mensaje y no se enviará ninguna alerta.",
+
+	"chart.price.title":  "Historial de precios (90 días)",
+	"chart.price.alt":    "Gráfico del historial de precios",
+	"chart.volume.title": "Volumen negociado (90 días)",
+	"chart.volume.alt":   "Gráfico del volumen negociado",
+	"chart.stats":        "Mín: %s | Máx: %s | Media: %s",
+	"chart.period":       "Del %s al %s",
+
+	"feargreed.title":         "Índice Fear & Greed del mercado",
+	"feargreed.alt":           "Índice Fear & Greed",
+	"feargreed.extreme fear":  "Miedo extremo",
+	"feargreed.fear":          "Miedo",
+	"feargreed.neutral":       "Neutral",
+	"feargreed.greed":         "Codicia",
+	"feargreed.extreme greed": "Codicia extrema",
+
+	"actions.manage":            "Gestionar esta alerta:",
+	"action.snooze_1h":          "Silenciar durante 1h",
+	"action.snooze_24h":         "Silenciar durante 24h",
+	"action.disable_rule":       "Desactivar esta condición",
+	"action.unsubscribe_symbol": "Cancelar alertas de %s",
+
+	"page.team":                      "Equipo Crypto Alerts",
+	"page.invalid.title":             "Enlace inválido",
+	"page.invalid":                   "Este enlace es inválido o ha caducado.",
+	"page.error.title":               "Error",
+	"page.error":                     "No fue posible completar la acción. Inténtelo de nuevo más tarde.",
+	"page.action.title":              "Confirmar acción",
+	"page.action.button":             "Confirmar",
+	"page.action.question":           "¿Desea %s?",
+	"page.action.done.title":         "¡Listo!",
+	"page.action.done":               "Su solicitud para %s se completó.",
+	"page.action.not_found.title":    "Alerta no encontrada",
+	"page.action.not_found":          "Esta alerta ya no existe.",
+	"page.action.snooze_1h":          "silenciar esta alerta de %s durante 1 hora",
+	"page.action.snooze_24h":         "silenciar esta alerta de %s durante 24 horas",
+	"page.action.disable_rule":       "desactivar esta condición de la alerta de %s",
+	"page.action.unsubscribe_symbol": "cancelar todas sus alertas de %s",
+	"page.unsubscribe.title":         "Cancelar suscripción",
+	"page.unsubscribe.button":        "Cancelar suscripción",
+	"page.unsubscribe.question":      "¿Desea dejar de recibir todos los correos de Crypto Alerts en <strong>%s</strong>?",
+	"page.unsubscribe.done.title":    "Suscripción cancelada",
+	"page.unsubscribe.done":          "<strong>%s</strong> ya no recibirá correos de Crypto Alerts.",
+	"page.confirm.title":             "Confirmar correo",
+	"page.confirm.button":            "Confirmar correo",
+	"page.confirm.question":          "Confirme que desea recibir alertas en <strong>%s</strong>.",
+	"page.confirm.invalid":           "Este enlace es inválido o ha caducado. Cree la alerta de nuevo para recibir un nuevo enlace.",
+	"page.confirm.done.title":        "Correo confirmado",
+	"page.confirm.done":              "¡Gracias! Se activaron %d alertas.",
+}
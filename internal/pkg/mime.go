@@ -0,0 +1,214 @@
+package pkg
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// MailMessage is an email rendered as multipart/alternative, with the text
+// part derived from the HTML one when not given. Images embedded in the
+// HTML as data URIs are sent as inline attachments.
+type MailMessage struct {
+	From     mail.Address
+	To       string
+	Subject  string
+	HTMLBody string
+	TextBody string
+	Date     time.Time
+
+	// Extra headers such as List-Unsubscribe, written in sorted order
+	Headers map[string]string
+}
+
+// Bytes renders the message with CRLF line endings, ready for the SMTP DATA
+// command.
+func (m *MailMessage) Bytes() ([]byte, error) {
+	date := m.Date
+	if date.IsZero() {
+		date = time.Now()
+	}
+	textBody := m.TextBody
+	if textBody == "" {
+		textBody = HTMLToText(m.HTMLBody)
+	}
+
+	messageID, err := newMessageID(m.From.Address)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+
+	writeHeader := func(key string, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	writeHeader("From", m.From.String())
+	writeHeader("To", m.To)
+	writeHeader("Subject", encodeHeaderValue(m.Subject))
+	writeHeader("Date", date.Format(time.RFC1123Z))
+	writeHeader("Message-ID", messageID)
+	writeHeader("MIME-Version", "1.0")
+
+	keys := make([]string, 0, len(m.Headers))
+	for key := range m.Headers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		writeHeader(key, m.Headers[key])
+	}
+
+	writeHeader("Content-Type", fmt.Sprintf("multipart/alternative;\r\n boundary=%q", writer.Boundary()))
+	buf.WriteString("\r\n")
+
+	if err := writeQuotedPrintablePart(writer, "text/plain; charset=utf-8", textBody); err != nil {
+		return nil, err
+	}
+
+	htmlBody, images := extractInlineImages(m.HTMLBody, strings.Trim(messageID, "<>"))
+	if len(images) == 0 {
+		if err := writeQuotedPrintablePart(writer, "text/html; charset=utf-8", htmlBody); err != nil {
+			return nil, err
+		}
+	} else if err := writeRelatedPart(writer, htmlBody, images); err != nil {
+		return nil, err
+	}
+
+	if err := writer.Close(); err != nil {
+		return nil, fmt.Errorf("erro ao finalizar e-mail: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+// inlineImage is an image referenced from the HTML part by its Content-ID.
+type inlineImage struct {
+	contentID   string
+	contentType string
+	data        string
+}
+
+var dataURIPattern = regexp.MustCompile(`(src=['"])data:(image/[a-z0-9.+-]+);base64,([A-Za-z0-9+/=]+)(['"])`)
+
+// extractInlineImages replaces base64 data URIs in img tags, which many
+// mail clients refuse to display, with cid: references to inline parts.
+func extractInlineImages(html string, idSuffix string) (string, []inlineImage) {
+	var images []inlineImage
+	html = dataURIPattern.ReplaceAllStringFunc(html, func(match string) string {
+		groups := dataURIPattern.FindStringSubmatch(match)
+		image := inlineImage{
+			contentID:   fmt.Sprintf("image%d.%s", len(images)+1, idSuffix),
+			contentType: groups[2],
+			data:        groups[3],
+		}
+		images = append(images, image)
+		return groups[1] + "cid:" + image.contentID + groups[4]
+	})
+	return html, images
+}
+
+func writeQuotedPrintablePart(writer *multipart.Writer, contentType string, body string) error {
+	partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"quoted-printable"},
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao montar parte do e-mail: %w", err)
+	}
+
+	encoder := quotedprintable.NewWriter(partWriter)
+	if _, err := encoder.Write([]byte(toCRLF(body))); err != nil {
+		return fmt.Errorf("erro ao codificar parte do e-mail: %w", err)
+	}
+	if err := encoder.Close(); err != nil {
+		return fmt.Errorf("erro ao codificar parte do e-mail: %w", err)
+	}
+	return nil
+}
+
+// writeRelatedPart nests the HTML and its images in a multipart/related
+// part so the cid: references resolve.
+func writeRelatedPart(writer *multipart.Writer, htmlBody string, images []inlineImage) error {
+	var related bytes.Buffer
+	relatedWriter := multipart.NewWriter(&related)
+
+	if err := writeQuotedPrintablePart(relatedWriter, "text/html; charset=utf-8", htmlBody); err != nil {
+		return err
+	}
+
+	for i, image := range images {
+		extension := strings.TrimPrefix(image.contentType, "image/")
+		partWriter, err := relatedWriter.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {image.contentType},
+			"Content-Transfer-Encoding": {"base64"},
+			"Content-ID":                {"<" + image.contentID + ">"},
+			"Content-Disposition":       {fmt.Sprintf("inline; filename=\"chart-%d.%s\"", i+1, extension)},
+		})
+		if err != nil {
+			return fmt.Errorf("erro ao montar imagem do e-mail: %w", err)
+		}
+
+		for data := image.data; data != ""; {
+			line := data[:min(76, len(data))]
+			data = data[len(line):]
+			if _, err := partWriter.Write([]byte(line + "\r\n")); err != nil {
+				return fmt.Errorf("erro ao codificar imagem do e-mail: %w", err)
+			}
+		}
+	}
+
+	if err := relatedWriter.Close(); err != nil {
+		return fmt.Errorf("erro ao finalizar e-mail: %w", err)
+	}
+
+	partWriter, err := writer.CreatePart(textproto.MIMEHeader{
+		"Content-Type": {fmt.Sprintf("multipart/related; type=\"text/html\";\r\n boundary=%q", relatedWriter.Boundary())},
+	})
+	if err != nil {
+		return fmt.Errorf("erro ao montar parte do e-mail: %w", err)
+	}
+	if _, err := partWriter.Write(related.Bytes()); err != nil {
+		return fmt.Errorf("erro ao montar parte do e-mail: %w", err)
+	}
+	return nil
+}
+
+// encodeHeaderValue applies RFC 2047 to non-ASCII values, folding long
+// subjects between encoded words.
+func encodeHeaderValue(value string) string {
+	encoded := mime.QEncoding.Encode("utf-8", value)
+	if encoded == value {
+		return value
+	}
+	return strings.ReplaceAll(encoded, "?= =?", "?=\r\n =?")
+}
+
+func newMessageID(from string) (string, error) {
+	domain := "localhost"
+	if at := strings.LastIndex(from, "@"); at >= 0 && at < len(from)-1 {
+		domain = from[at+1:]
+	}
+
+	random := make([]byte, 16)
+	if _, err := rand.Read(random); err != nil {
+		return "", fmt.Errorf("erro ao gerar Message-ID: %w", err)
+	}
+
+	return fmt.Sprintf("<%d.%s@%s>", time.Now().UnixNano(), hex.EncodeToString(random), domain), nil
+}
+
+func toCRLF(body string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(body, "\r\n", "\n"), "\n", "\r\n")
+}
@@ -0,0 +1,190 @@
+package pkg
+
+import (
+	"bytes"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/mail"
+	"strings"
+	"testing"
+	"time"
+)
+
+func readMailMessage(t *testing.T, message *MailMessage) *mail.Message {
+	t.Helper()
+
+	raw, err := message.Bytes()
+	if err != nil {
+		t.Fatalf("Bytes: %v", err)
+	}
+	if bytes.Contains(bytes.ReplaceAll(raw, []byte("\r\n"), nil), []byte("\n")) {
+		t.Fatal("message has bare LF line endings")
+	}
+
+	parsed, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	return parsed
+}
+
+type mailPart struct {
+	contentType string
+	header      map[string][]string
+	body        string
+}
+
+func readMultipart(t *testing.T, contentType string, body io.Reader) (string, []mailPart) {
+	t.Helper()
+
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		t.Fatalf("Content-Type %q: %v", contentType, err)
+	}
+
+	var parts []mailPart
+	reader := multipart.NewReader(body, params["boundary"])
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		data, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		parts = append(parts, mailPart{contentType: part.Header.Get("Content-Type"), header: part.Header, body: string(data)})
+	}
+	return mediaType, parts
+}
+
+func TestMailMessageHeaders(t *testing.T) {
+	date := time.Date(2026, 3, 14, 15, 9, 26, 0, time.FixedZone("BRT", -3*60*60))
+	message := readMailMessage(t, &MailMessage{
+		From:     mail.Address{Name: "Crypto Alerts", Address: "alerts@example.com"},
+		To:       "user@example.com",
+		Subject:  strings.Repeat("Alerta de preço do Ethereum ", 4),
+		HTMLBody: "<p>corpo</p>",
+		Date:     date,
+		Headers: map[string]string{
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+			"List-Unsubscribe":      "<https://alerts.example.com/unsubscribe?token=abc>",
+		},
+	})
+
+	if got := message.Header.Get("Date"); got != "Sat, 14 Mar 2026 15:09:26 -0300" {
+		t.Errorf("Date = %q", got)
+	}
+	if got := message.Header.Get("MIME-Version"); got != "1.0" {
+		t.Errorf("MIME-Version = %q", got)
+	}
+	if got := message.Header.Get("List-Unsubscribe"); got != "<https://alerts.example.com/unsubscribe?token=abc>" {
+		t.Errorf("List-Unsubscribe = %q", got)
+	}
+	if got := message.Header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", got)
+	}
+
+	messageID := message.Header.Get("Message-ID")
+	if !strings.HasPrefix(messageID, "<") || !strings.HasSuffix(messageID, "@example.com>") || strings.Count(messageID, "@") != 1 {
+		t.Errorf("Message-ID = %q, want <...@example.com>", messageID)
+	}
+
+	rawSubject := message.Header.Get("Subject")
+	if strings.Count(rawSubject, "=?utf-8?q?") < 2 {
+		t.Errorf("long Subject %q was not split into encoded words", rawSubject)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(rawSubject)
+	if err != nil || subject != strings.Repeat("Alerta de preço do Ethereum ", 4) {
+		t.Errorf("decoded Subject = %q (%v)", subject, err)
+	}
+}
+
+func TestEncodeHeaderValue(t *testing.T) {
+	if got := encodeHeaderValue("BTC up 5%"); got != "BTC up 5%" {
+		t.Errorf("ASCII value encoded as %q", got)
+	}
+
+	encoded := encodeHeaderValue(strings.Repeat("preço ", 20))
+	for _, line := range strings.Split(encoded, "\r\n") {
+		if len(line) > 76 {
+			t.Errorf("folded line of %d characters: %q", len(line), line)
+		}
+		if line != strings.TrimLeft(line, " ") && !strings.HasPrefix(line, " =?") {
+			t.Errorf("continuation line %q does not start with an encoded word", line)
+		}
+	}
+}
+
+func TestMailMessageAlternativeParts(t *testing.T) {
+	message := readMailMessage(t, &MailMessage{
+		From:     mail.Address{Address: "alerts@example.com"},
+		To:       "user@example.com",
+		Subject:  "BTC",
+		HTMLBody: "<h1>BTC</h1>\n<p>Preço: <strong>US$ 65.000,00</strong></p>",
+	})
+
+	mediaType, parts := readMultipart(t, message.Header.Get("Content-Type"), message.Body)
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", mediaType)
+	}
+	if len(parts) != 2 {
+		t.Fatalf("got %d parts, want text and html", len(parts))
+	}
+	if parts[0].contentType != "text/plain; charset=utf-8" || parts[1].contentType != "text/html; charset=utf-8" {
+		t.Fatalf("parts are %q and %q, want text/plain then text/html", parts[0].contentType, parts[1].contentType)
+	}
+	if strings.Contains(parts[0].body, "<") || !strings.Contains(parts[0].body, "Preço: US$ 65.000,00") {
+		t.Errorf("text part = %q", parts[0].body)
+	}
+	if parts[1].body != "<h1>BTC</h1>\r\n<p>Preço: <strong>US$ 65.000,00</strong></p>" {
+		t.Errorf("html part = %q", parts[1].body)
+	}
+}
+
+func TestMailMessageInlineImages(t *testing.T) {
+	png := base64.StdEncoding.EncodeToString(bytes.Repeat([]byte{0x89, 'P', 'N', 'G'}, 40))
+	message := readMailMessage(t, &MailMessage{
+		From:     mail.Address{Address: "alerts@example.com"},
+		To:       "user@example.com",
+		Subject:  "BTC",
+		HTMLBody: `<p>Gráfico</p><img src="data:image/png;base64,` + png + `" alt="chart">`,
+		TextBody: "Gráfico",
+	})
+
+	_, parts := readMultipart(t, message.Header.Get("Content-Type"), message.Body)
+	if len(parts) != 2 || parts[0].body != "Gráfico" {
+		t.Fatalf("got %d parts, want the given text part first", len(parts))
+	}
+
+	mediaType, related := readMultipart(t, parts[1].contentType, strings.NewReader(parts[1].body))
+	if mediaType != "multipart/related" {
+		t.Fatalf("html alternative is %q, want multipart/related", mediaType)
+	}
+	if len(related) != 2 {
+		t.Fatalf("got %d related parts, want html and one image", len(related))
+	}
+
+	html, image := related[0], related[1]
+	if strings.Contains(html.body, "data:image") {
+		t.Error("data URI left in the HTML part")
+	}
+	contentID := strings.Trim(image.header["Content-Id"][0], "<>")
+	if !strings.Contains(html.body, `src="cid:`+contentID+`"`) {
+		t.Errorf("html part %q does not reference %q", html.body, contentID)
+	}
+	if image.contentType != "image/png" || image.header["Content-Transfer-Encoding"][0] != "base64" {
+		t.Errorf("image part headers = %v", image.header)
+	}
+	if got := image.header["Content-Disposition"][0]; got != `inline; filename="chart-1.png"` {
+		t.Errorf("Content-Disposition = %q", got)
+	}
+	if data := strings.ReplaceAll(image.body, "\r\n", ""); data != png {
+		t.Errorf("image data does not round-trip")
+	}
+}
@@ -0,0 +1,94 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"time"
+)
+
+func PairSymbol(base string, quote string) string {
+	if quote == "" {
+		return base
+	}
+	return base + "/" + quote
+}
+
+// BuildPairQuote expresses base in units of quote. Percent changes are
+// derived from both USD changes, so a pair moves only when one asset
+// outperforms the other. Volume and market cap stay those of the base asset
+// in USD.
+func BuildPairQuote(base *entity.CryptoCurrency, quote *entity.CryptoCurrency) *entity.CryptoCurrency {
+	if base == nil || quote == nil || quote.Price == 0 {
+		return nil
+	}
+
+	return &entity.CryptoCurrency{
+		Name:               base.Name + "/" + quote.Name,
+		Price:              base.Price / quote.Price,
+		Volume24h:          base.Volume24h,
+		VolumeChange24h:    base.VolumeChange24h,
+		MarketCap:          base.MarketCap,
+		MarketCapDominance: base.MarketCapDominance,
+		PercentChange1h:    ratioPercentChange(base.PercentChange1h, quote.PercentChange1h),
+		PercentChange24h:   ratioPercentChange(base.PercentChange24h, quote.PercentChange24h),
+		PercentChange7d:    ratioPercentChange(base.PercentChange7d, quote.PercentChange7d),
+		PercentChange30d:   ratioPercentChange(base.PercentChange30d, quote.PercentChange30d),
+		PercentChange60d:   ratioPercentChange(base.PercentChange60d, quote.PercentChange60d),
+		PercentChange90d:   ratioPercentChange(base.PercentChange90d, quote.PercentChange90d),
+		LastUpdated:        base.LastUpdated,
+	}
+}
+
+func ratioPercentChange(basePercent float64, quotePercent float64) float64 {
+	if quotePercent == -100 {
+		return 0
+	}
+	return ((1+basePercent/100)/(1+quotePercent/100) - 1) * 100
+}
+
+// BuildPairHistory aligns both histories into buckets of the given size (a
+// day for daily history, an hour for hourly) and divides the base price by the
+// quote price. Buckets missing from either side are skipped.
+func BuildPairHistory(base *entity.HistoricalPriceData, quote *entity.HistoricalPriceData, bucket time.Duration) *entity.HistoricalPriceData {
+	if base == nil || quote == nil {
+		return nil
+	}
+
+	bucketMillis := bucket.Milliseconds()
+
+	quotePrices := make(map[int64]float64, len(quote.Prices))
+	for _, point := range quote.Prices {
+		quotePrices[point.Timestamp/bucketMillis] = point.Price
+	}
+
+	pair := &entity.HistoricalPriceData{
+		Symbol:  PairSymbol(base.Symbol, quote.Symbol),
+		Quote:   quote.Symbol,
+		Prices:  make([]entity.PriceHistoryPoint, 0, len(base.Prices)),
+		Volumes: make([]entity.VolumePoint, 0, len(base.Volumes)),
+	}
+
+	buckets := make(map[int64]bool, len(base.Prices))
+	for _, point := range base.Prices {
+		key := point.Timestamp / bucketMillis
+		quotePrice, ok := quotePrices[key]
+		if !ok || quotePrice == 0 {
+			continue
+		}
+
+		pair.Prices = append(pair.Prices, entity.PriceHistoryPoint{
+			Timestamp: point.Timestamp,
+			Price:     point.Price / quotePrice,
+		})
+		buckets[key] = true
+	}
+
+	for _, point := range base.Volumes {
+		if buckets[point.Timestamp/bucketMillis] {
+			pair.Volumes = append(pair.Volumes, point)
+		}
+	}
+
+	pair.RefreshStats()
+
+	return pair
+}
@@ -0,0 +1,50 @@
+package pkg
+
+const (
+	PnLKindPercent = "percent"
+	PnLKindAmount  = "amount"
+)
+
+func FormatPnLEmailSubject(message AlertMessage) string {
+	locale := GetLocale(message.Locale)
+	emoji := "🟢"
+	key := "pnl.subject.up"
+	if message.Direction == "down" {
+		emoji = "🔴"
+		key = "pnl.subject.down"
+	}
+
+	return emoji + " " + locale.T(key, message.Symbol, locale.SignedMoney(message.PnLAmount), locale.SignedPercent(message.PnLPercent))
+}
+
+func FormatPnLEmailBody(message AlertMessage) (EmailBody, error) {
+	return renderEmail("pnl", newAlertEmailData(message))
+}
+
+func FormatPortfolioEmailSubject(message AlertMessage) string {
+	locale := GetLocale(message.Locale)
+	emoji := "📈"
+	key := "portfolio.subject.up"
+	if message.Direction == "down" {
+		emoji = "📉"
+		key = "portfolio.subject.down"
+	}
+
+	return emoji + " " + locale.T(key, locale.Money(locale.LargeNumber(message.Threshold)), locale.Money(locale.LargeNumber(message.PortfolioValue)))
+}
+
+func FormatPortfolioEmailBody(message AlertMessage) (EmailBody, error) {
+	data := portfolioEmailData{alertEmailData: newAlertEmailData(message)}
+	data.PnL = message.PortfolioValue - message.PortfolioCost
+	if message.PortfolioCost > 0 {
+		data.PnLPercent = data.PnL / message.PortfolioCost * 100
+	}
+
+	return renderEmail("portfolio", data)
+}
+
+type portfolioEmailData struct {
+	alertEmailData
+	PnL        float64
+	PnLPercent float64
+}
@@ -0,0 +1,49 @@
+package pkg
+
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Prices of one unit or more are shown with two decimals, the way they are
+// quoted, while smaller ones keep priceSignificantDigits significant digits
+// so tokens such as SHIB don't print as $0.00.
+const (
+	priceSignificantDigits = 4
+	minPriceDecimals       = 2
+	MaxPriceDecimals       = 12
+)
+
+// PriceDecimals returns how many decimals value is printed with, dropping
+// trailing zeros beyond the second decimal.
+func PriceDecimals(value float64) int {
+	decimals := significantDecimals(value, priceSignificantDigits)
+	formatted := strconv.FormatFloat(math.Abs(value), 'f', decimals, 64)
+	for decimals > minPriceDecimals && strings.HasSuffix(formatted, "0") {
+		formatted = formatted[:len(formatted)-1]
+		decimals--
+	}
+	return decimals
+}
+
+// RoundPrice rounds value to the precision it is printed with.
+func RoundPrice(value float64) float64 {
+	rounded, _ := strconv.ParseFloat(strconv.FormatFloat(value, 'f', significantDecimals(value, priceSignificantDigits), 64), 64)
+	return rounded
+}
+
+// TickDecimals returns how many decimals axis ticks step apart need so
+// neighbouring ticks don't print alike.
+func TickDecimals(value float64, step float64) int {
+	return max(PriceDecimals(value), significantDecimals(step, 1))
+}
+
+func significantDecimals(value float64, digits int) int {
+	abs := math.Abs(value)
+	if abs >= 1 || abs == 0 {
+		return minPriceDecimals
+	}
+	decimals := digits - 1 - int(math.Floor(math.Log10(abs)))
+	return min(max(decimals, minPriceDecimals), MaxPriceDecimals)
+}
@@ -0,0 +1,23 @@
+package pkg
+
+import "crypto-alerts/internal/entity"
+
+// queuedSummaryLocale takes the language of the summary from the queued
+// alerts, which were grouped per locale when they were held back.
+func queuedSummaryLocale(alerts []*entity.QueuedAlert) *Locale {
+	if len(alerts) == 0 {
+		return GetLocale(DefaultLocale)
+	}
+	return GetLocale(alerts[0].Locale)
+}
+
+func FormatQueuedSummaryEmailSubject(alerts []*entity.QueuedAlert) string {
+	return queuedSummaryLocale(alerts).T("queued.subject", len(alerts))
+}
+
+func FormatQueuedSummaryEmailBody(alerts []*entity.QueuedAlert) (EmailBody, error) {
+	return renderEmail("queued_summary", struct {
+		Alerts []*entity.QueuedAlert
+		L      *Locale
+	}{alerts, queuedSummaryLocale(alerts)})
+}
@@ -0,0 +1,25 @@
+package pkg
+
+const (
+	RangeKindNewHigh  = "new_high"
+	RangeKindNewLow   = "new_low"
+	RangeKindDrawdown = "drawdown"
+)
+
+func FormatRangeEmailSubject(message AlertMessage) string {
+	locale := GetLocale(message.Locale)
+	price := locale.Price(message.Price, message.QuoteSymbol)
+
+	switch message.RangeKind {
+	case RangeKindNewHigh:
+		return "🚀 " + locale.T("range.subject.new_high", message.Symbol, message.RangeLookbackDays, price)
+	case RangeKindNewLow:
+		return "📉 " + locale.T("range.subject.new_low", message.Symbol, message.RangeLookbackDays, price)
+	default:
+		return "🔻 " + locale.T("range.subject.drawdown", message.Symbol, locale.Percent(-message.DrawdownPercent), message.RangeLookbackDays, price)
+	}
+}
+
+func FormatRangeEmailBody(message AlertMessage) (EmailBody, error) {
+	return renderEmail("range", newAlertEmailData(message))
+}
@@ -0,0 +1,65 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"math"
+)
+
+const tradingDaysPerYear = 365
+
+// RealizedVolatility returns the annualized standard deviation of daily log
+// returns over the last window days, in percent. It needs window+1 prices.
+func RealizedVolatility(prices []entity.PriceHistoryPoint, window int) (float64, bool) {
+	returns, ok := dailyLogReturns(prices, window)
+	if !ok || len(returns) < 2 {
+		return 0, false
+	}
+
+	var sum float64
+	for _, r := range returns {
+		sum += r
+	}
+	mean := sum / float64(len(returns))
+
+	var squares float64
+	for _, r := range returns {
+		squares += (r - mean) * (r - mean)
+	}
+	variance := squares / float64(len(returns)-1)
+
+	return math.Sqrt(variance) * math.Sqrt(tradingDaysPerYear) * 100, true
+}
+
+// AverageRangePercent is an ATR-like measure built from daily closes, since
+// CoinGecko's market chart has no intraday high/low: the mean absolute
+// day-over-day move over the last window days, in percent.
+func AverageRangePercent(prices []entity.PriceHistoryPoint, window int) (float64, bool) {
+	returns, ok := dailyLogReturns(prices, window)
+	if !ok || len(returns) == 0 {
+		return 0, false
+	}
+
+	var sum float64
+	for _, r := range returns {
+		sum += math.Abs(math.Exp(r) - 1)
+	}
+
+	return sum / float64(len(returns)) * 100, true
+}
+
+func dailyLogReturns(prices []entity.PriceHistoryPoint, window int) ([]float64, bool) {
+	if window < 1 || len(prices) < window+1 {
+		return nil, false
+	}
+
+	recent := prices[len(prices)-window-1:]
+	returns := make([]float64, 0, window)
+	for i := 1; i < len(recent); i++ {
+		if recent[i-1].Price <= 0 || recent[i].Price <= 0 {
+			return nil, false
+		}
+		returns = append(returns, math.Log(recent[i].Price/recent[i-1].Price))
+	}
+
+	return returns, true
+}
@@ -0,0 +1,11 @@
+package pkg
+
+func FormatVolatilityEmailSubject(message AlertMessage) string {
+	locale := GetLocale(message.Locale)
+	return "🌪️ " + locale.T("volatility.subject", message.Symbol, message.ShortWindow, locale.Number(message.VolatilityMultiple, 1),
+		message.LongWindow, locale.Number(message.ShortVolatility, 0)+"%", locale.Number(message.LongVolatility, 0)+"%")
+}
+
+func FormatVolatilityEmailBody(message AlertMessage) (EmailBody, error) {
+	return renderEmail("volatility", newAlertEmailData(message))
+}
@@ -0,0 +1,38 @@
+package pkg
+
+import (
+	"crypto-alerts/internal/entity"
+	"time"
+)
+
+// PercentChangeOver computes the percent change from the price at now-window
+// to currentPrice, using the latest history point at or before that instant.
+// The point must be no older than tolerance, so gaps in the history never
+// silently stretch the window.
+func PercentChangeOver(
+	history *entity.HistoricalPriceData,
+	currentPrice float64,
+	window time.Duration,
+	tolerance time.Duration,
+	now time.Time,
+) (float64, bool) {
+	if history == nil || len(history.Prices) == 0 {
+		return 0, false
+	}
+
+	target := now.Add(-window).UnixMilli()
+
+	var reference *entity.PriceHistoryPoint
+	for i := range history.Prices {
+		if history.Prices[i].Timestamp > target {
+			break
+		}
+		reference = &history.Prices[i]
+	}
+
+	if reference == nil || reference.Price == 0 || target-reference.Timestamp > tolerance.Milliseconds() {
+		return 0, false
+	}
+
+	return (currentPrice - reference.Price) / reference.Price * 100, true
+}
@@ -24,8 +24,16 @@ var coinGeckoIDMap = map[string]string{
 	"AVAX":  "avalanche-2",
 }
 
+// CoinGecko picks the granularity from the requested range when no interval
+// is given: hourly points for 2 to 90 days.
+const (
+	minHourlyDays = 2
+	maxHourlyDays = 90
+)
+
 type CoinGeckoRepository interface {
 	GetHistoricalPrices(symbol string, days int) (*entity.HistoricalPriceData, error)
+	GetHourlyPrices(symbol string, days int) (*entity.HistoricalPriceData, error)
 }
 
 type coinGeckoRepo struct {
@@ -45,12 +53,21 @@ func NewCoinGeckoRepository(cfg *config.Config) CoinGeckoRepository {
 }
 
 func (r *coinGeckoRepo) GetHistoricalPrices(symbol string, days int) (*entity.HistoricalPriceData, error) {
+	return r.getMarketChart(symbol, fmt.Sprintf("days=%d&interval=daily", days))
+}
+
+func (r *coinGeckoRepo) GetHourlyPrices(symbol string, days int) (*entity.HistoricalPriceData, error) {
+	days = min(max(days, minHourlyDays), maxHourlyDays)
+	return r.getMarketChart(symbol, fmt.Sprintf("days=%d", days))
+}
+
+func (r *coinGeckoRepo) getMarketChart(symbol string, rangeQuery string) (*entity.HistoricalPriceData, error) {
 	coinID, exists := coinGeckoIDMap[strings.ToUpper(symbol)]
 	if !exists {
 		return nil, fmt.Errorf("símbolo %s não suportado pela CoinGecko", symbol)
 	}
 
-	url := fmt.Sprintf("%s/coins/%s/market_chart?vs_currency=usd&days=%d&interval=daily", r.domain, coinID, days)
+	url := fmt.Sprintf("%s/coins/%s/market_chart?vs_currency=usd&%s", r.domain, coinID, rangeQuery)
 
 	req, err := http.NewRequest("GET", url, nil)
 	if err != nil {
@@ -78,77 +95,34 @@ func (r *coinGeckoRepo) GetHistoricalPrices(symbol string, days int) (*entity.Hi
 	}
 
 	historicalData := &entity.HistoricalPriceData{
-		Symbol:    symbol,
-		Prices:    make([]entity.PriceHistoryPoint, 0, len(apiResponse.Prices)),
-		Volumes:   make([]entity.VolumePoint, 0, len(apiResponse.TotalVolumes)),
-		DaysCount: len(apiResponse.Prices),
+		Symbol:  symbol,
+		Prices:  make([]entity.PriceHistoryPoint, 0, len(apiResponse.Prices)),
+		Volumes: make([]entity.VolumePoint, 0, len(apiResponse.TotalVolumes)),
 	}
 
-	var priceSum float64
-	minPrice := float64(0)
-	maxPrice := float64(0)
-
-	for i, priceData := range apiResponse.Prices {
+	for _, priceData := range apiResponse.Prices {
 		if len(priceData) < 2 {
 			continue
 		}
 
-		timestamp := int64(priceData[0])
-		price := priceData[1]
-
 		historicalData.Prices = append(historicalData.Prices, entity.PriceHistoryPoint{
-			Timestamp: timestamp,
-			Price:     price,
+			Timestamp: int64(priceData[0]),
+			Price:     priceData[1],
 		})
-
-		priceSum += price
-
-		if i == 0 || price < minPrice {
-			minPrice = price
-		}
-		if i == 0 || price > maxPrice {
-			maxPrice = price
-		}
-	}
-
-	if len(historicalData.Prices) > 0 {
-		historicalData.MinPrice = minPrice
-		historicalData.MaxPrice = maxPrice
-		historicalData.AvgPrice = priceSum / float64(len(historicalData.Prices))
 	}
 
-	var volumeSum float64
-	minVolume := float64(0)
-	maxVolume := float64(0)
-
-	for i, volumeData := range apiResponse.TotalVolumes {
+	for _, volumeData := range apiResponse.TotalVolumes {
 		if len(volumeData) < 2 {
 			continue
 		}
 
-		timestamp := int64(volumeData[0])
-		volume := volumeData[1]
-
 		historicalData.Volumes = append(historicalData.Volumes, entity.VolumePoint{
-			Timestamp: timestamp,
-			Volume:    volume,
+			Timestamp: int64(volumeData[0]),
+			Volume:    volumeData[1],
 		})
-
-		volumeSum += volume
-
-		if i == 0 || volume < minVolume {
-			minVolume = volume
-		}
-		if i == 0 || volume > maxVolume {
-			maxVolume = volume
-		}
 	}
 
-	if len(historicalData.Volumes) > 0 {
-		historicalData.MinVolume = minVolume
-		historicalData.MaxVolume = maxVolume
-		historicalData.AvgVolume = volumeSum / float64(len(historicalData.Volumes))
-	}
+	historicalData.RefreshStats()
 
 	return historicalData, nil
 }
@@ -0,0 +1,120 @@
+package db
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"database/sql"
+	"fmt"
+	"time"
+)
+
+type AlertHistoryRepository interface {
+	Record(entry *entity.AlertHistory) error
+	GetByEmailSince(email string, since time.Time) ([]*entity.AlertHistory, error)
+	GetAfter(afterID int64, email string, symbol string, limit int) ([]*entity.AlertHistory, error)
+}
+
+type AlertHistoryPostgres struct {
+	db *pkg.DB
+}
+
+func NewAlertHistoryRepository(db *pkg.DB) AlertHistoryRepository {
+	return &AlertHistoryPostgres{db: db}
+}
+
+func (r *AlertHistoryPostgres) Record(entry *entity.AlertHistory) error {
+	return insertAlertHistory(r.db.Conn, entry)
+}
+
+// rowQuerier is satisfied by both *sql.DB and *sql.Tx.
+type rowQuerier interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}
+
+func insertAlertHistory(querier rowQuerier, entry *entity.AlertHistory) error {
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	// Sent as text, since lib/pq would encode []byte as bytea
+	var message interface{}
+	if len(entry.Message) > 0 {
+		message = string(entry.Message)
+	}
+
+	err := querier.QueryRow(
+		`INSERT INTO alert_history (threshold_id, email, symbol, period, direction, price, subject, created_at, message)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9) RETURNING id`,
+		entry.ThresholdID,
+		entry.Email,
+		entry.Symbol,
+		entry.Period,
+		entry.Direction,
+		entry.Price,
+		entry.Subject,
+		entry.CreatedAt,
+		message,
+	).Scan(&entry.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar histórico do alerta: %w", err)
+	}
+
+	return nil
+}
+
+const alertHistoryColumns = `id, threshold_id, email, symbol, period, direction, price, subject, created_at, message`
+
+func (r *AlertHistoryPostgres) GetByEmailSince(email string, since time.Time) ([]*entity.AlertHistory, error) {
+	return r.query(
+		fmt.Sprintf(`SELECT %s FROM alert_history WHERE email = $1 AND created_at > $2 ORDER BY created_at, id`, alertHistoryColumns),
+		email, since,
+	)
+}
+
+// GetAfter returns the entries recorded after afterID in the order they were
+// recorded, optionally only those of one email or symbol.
+func (r *AlertHistoryPostgres) GetAfter(afterID int64, email string, symbol string, limit int) ([]*entity.AlertHistory, error) {
+	return r.query(
+		fmt.Sprintf(`SELECT %s FROM alert_history
+		WHERE id > $1 AND ($2 = '' OR email = $2) AND ($3 = '' OR symbol = $3)
+		ORDER BY id LIMIT $4`, alertHistoryColumns),
+		afterID, email, symbol, limit,
+	)
+}
+
+func (r *AlertHistoryPostgres) query(query string, args ...interface{}) ([]*entity.AlertHistory, error) {
+	rows, err := r.db.Conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar histórico de alertas: %w", err)
+	}
+	defer rows.Close()
+
+	var entries []*entity.AlertHistory
+	for rows.Next() {
+		entry := &entity.AlertHistory{}
+		var message []byte
+		err := rows.Scan(
+			&entry.ID,
+			&entry.ThresholdID,
+			&entry.Email,
+			&entry.Symbol,
+			&entry.Period,
+			&entry.Direction,
+			&entry.Price,
+			&entry.Subject,
+			&entry.CreatedAt,
+			&message,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan do histórico de alertas: %w", err)
+		}
+		entry.Message = message
+		entries = append(entries, entry)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre o histórico de alertas: %w", err)
+	}
+
+	return entries, nil
+}
@@ -3,15 +3,37 @@ package db
 import (
 	"crypto-alerts/internal/entity"
 	"crypto-alerts/internal/pkg"
+	"database/sql"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
 	"math/rand"
 	"time"
+
+	"github.com/lib/pq"
+)
+
+var (
+	ErrThresholdNotFound      = errors.New("threshold não encontrado")
+	ErrInvalidStateTransition = errors.New("transição de estado inválida")
 )
 
 type AlertThresholdRepository interface {
 	Create(threshold *entity.AlertThreshold) error
 	GetAllThresholds() ([]*entity.AlertThreshold, error)
+	GetThresholdsByEmail(email string) ([]*entity.AlertThreshold, error)
+	UpdatePriceObservation(id int64, price float64, crossDirection string) error
+	UpdateState(id int64, email string, state string) error
+	RecordTrigger(id int64, triggerCount int, state string) error
+	UpdateDepegConsecutiveScans(id int64, consecutiveScans int) error
+	UpdateVolatilityRegime(id int64, active bool) error
+	UpdateLastDigest(ids []int64, sentAt time.Time) error
+	Snooze(id int64, email string, until time.Time) error
+	DisableRule(thresholdID int64, ruleID int64, email string) error
+	PauseSymbol(email string, symbol string) (int, error)
+	ActivatePending(email string) (int, error)
+	ExpirePending(createdBefore time.Time) (int, error)
 }
 
 type AlertThresholdPostgres struct {
@@ -32,147 +54,117 @@ func (r *AlertThresholdPostgres) Create(threshold *entity.AlertThreshold) error
 			id, 
 			email, 
 			crypto_symbol,
-			
-			threshold_up_1h_percent, 
-			threshold_up_1h_enabled, 
-			threshold_down_1h_percent, 
-			threshold_down_1h_enabled,
-			
-			threshold_up_24h_percent, 
-			threshold_up_24h_enabled, 
-			threshold_down_24h_percent, 
-			threshold_down_24h_enabled,
-			
-			threshold_up_7d_percent, 
-			threshold_up_7d_enabled, 
-			threshold_down_7d_percent, 
-			threshold_down_7d_enabled,
-			
-			threshold_up_30d_percent, 
-			threshold_up_30d_enabled, 
-			threshold_down_30d_percent, 
-			threshold_down_30d_enabled,
-			
-			threshold_up_60d_percent, 
-			threshold_up_60d_enabled, 
-			threshold_down_60d_percent, 
-			threshold_down_60d_enabled,
-			
-			threshold_up_90d_percent, 
-			threshold_up_90d_enabled, 
-			threshold_down_90d_percent, 
-			threshold_down_90d_enabled,
-			
-			target_price_up,
-			target_price_up_enabled,
-			target_price_down,
-			target_price_down_enabled,
+			quote_symbol,
+
+			composite_rule,
+			composite_rule_enabled,
+
+			custom_expression,
+			custom_expression_enabled,
+
+			depeg_enabled,
+			depeg_peg_price,
+			depeg_band_bps,
+			depeg_sustained_scans,
+
+			range_lookback_days,
+			new_high_enabled,
+			new_low_enabled,
+			drawdown_percent,
+			drawdown_enabled,
+
+			volatility_enabled,
+			volatility_short_window,
+			volatility_long_window,
+			volatility_multiple,
+
+			state,
+			requested_state,
+			one_shot,
+			max_triggers,
+			trigger_count,
+			starts_at,
+			expires_at,
+
+			digest_frequency,
+			critical,
+			locale,
 			
 			created_at
 		) VALUES (
-			$1, $2, $3, 
-			$4, $5, $6, $7, 
-			$8, $9, $10, $11, 
-			$12, $13, $14, $15, 
-			$16, $17, $18, $19, 
-			$20, $21, $22, $23, 
-			$24, $25, $26, $27, 
-			$28, $29, $30, $31,
+			$1, $2, $3, $4,
+			$5, $6,
+			$7, $8,
+			$9, $10, $11, $12,
+			$13, $14, $15, $16, $17,
+			$18, $19, $20, $21,
+			$22, $23, $24, $25, $26, $27, $28,
+			$29, $30, $31,
 			$32
 		)
 	`
 
-	var up1hPercent, down1hPercent interface{}
-	var up24hPercent, down24hPercent interface{}
-	var up7dPercent, down7dPercent interface{}
-	var up30dPercent, down30dPercent interface{}
-	var up60dPercent, down60dPercent interface{}
-	var up90dPercent, down90dPercent interface{}
-	var targetPriceUp, targetPriceDown interface{}
-
-	if threshold.ThresholdUp1hPercent != nil {
-		up1hPercent = *threshold.ThresholdUp1hPercent
-	}
-	if threshold.ThresholdDown1hPercent != nil {
-		down1hPercent = *threshold.ThresholdDown1hPercent
-	}
-	if threshold.ThresholdUp24hPercent != nil {
-		up24hPercent = *threshold.ThresholdUp24hPercent
-	}
-	if threshold.ThresholdDown24hPercent != nil {
-		down24hPercent = *threshold.ThresholdDown24hPercent
+	var quoteSymbol interface{}
+	if threshold.QuoteSymbol != "" {
+		quoteSymbol = threshold.QuoteSymbol
 	}
-	if threshold.ThresholdUp7dPercent != nil {
-		up7dPercent = *threshold.ThresholdUp7dPercent
-	}
-	if threshold.ThresholdDown7dPercent != nil {
-		down7dPercent = *threshold.ThresholdDown7dPercent
-	}
-	if threshold.ThresholdUp30dPercent != nil {
-		up30dPercent = *threshold.ThresholdUp30dPercent
-	}
-	if threshold.ThresholdDown30dPercent != nil {
-		down30dPercent = *threshold.ThresholdDown30dPercent
-	}
-	if threshold.ThresholdUp60dPercent != nil {
-		up60dPercent = *threshold.ThresholdUp60dPercent
-	}
-	if threshold.ThresholdDown60dPercent != nil {
-		down60dPercent = *threshold.ThresholdDown60dPercent
-	}
-	if threshold.ThresholdUp90dPercent != nil {
-		up90dPercent = *threshold.ThresholdUp90dPercent
-	}
-	if threshold.ThresholdDown90dPercent != nil {
-		down90dPercent = *threshold.ThresholdDown90dPercent
+
+	var requestedState interface{}
+	if threshold.RequestedState != "" {
+		requestedState = threshold.RequestedState
 	}
-	if threshold.TargetPriceUp != nil {
-		targetPriceUp = *threshold.TargetPriceUp
+
+	compositeRule, err := marshalCompositeRule(threshold.CompositeRule)
+	if err != nil {
+		return err
 	}
-	if threshold.TargetPriceDown != nil {
-		targetPriceDown = *threshold.TargetPriceDown
+
+	tx, err := r.db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
 	}
+	defer tx.Rollback()
 
-	_, err := r.db.Conn.Exec(
+	_, err = tx.Exec(
 		sql,
 		id,
 		threshold.Email,
 		threshold.CryptoSymbol,
-
-		up1hPercent,
-		threshold.ThresholdUp1hEnabled,
-		down1hPercent,
-		threshold.ThresholdDown1hEnabled,
-
-		up24hPercent,
-		threshold.ThresholdUp24hEnabled,
-		down24hPercent,
-		threshold.ThresholdDown24hEnabled,
-
-		up7dPercent, // Threshold de alta 7d
-		threshold.ThresholdUp7dEnabled,
-		down7dPercent, // Threshold de baixa 7d
-		threshold.ThresholdDown7dEnabled,
-
-		up30dPercent, // Threshold de alta 30d
-		threshold.ThresholdUp30dEnabled,
-		down30dPercent,
-		threshold.ThresholdDown30dEnabled,
-
-		up60dPercent,
-		threshold.ThresholdUp60dEnabled,
-		down60dPercent,
-		threshold.ThresholdDown60dEnabled,
-
-		up90dPercent,
-		threshold.ThresholdUp90dEnabled,
-		down90dPercent,
-		threshold.ThresholdDown90dEnabled,
-
-		targetPriceUp,
-		threshold.TargetPriceUpEnabled,
-		targetPriceDown,
-		threshold.TargetPriceDownEnabled,
+		quoteSymbol,
+
+		compositeRule,
+		threshold.CompositeRuleEnabled,
+
+		threshold.CustomExpression,
+		threshold.CustomExpressionEnabled,
+
+		threshold.DepegEnabled,
+		threshold.DepegPegPrice,
+		threshold.DepegBandBps,
+		threshold.DepegSustainedScans,
+
+		threshold.RangeLookbackDays,
+		threshold.NewHighEnabled,
+		threshold.NewLowEnabled,
+		threshold.DrawdownPercent,
+		threshold.DrawdownEnabled,
+
+		threshold.VolatilityEnabled,
+		threshold.VolatilityShortWindow,
+		threshold.VolatilityLongWindow,
+		threshold.VolatilityMultiple,
+
+		threshold.State,
+		requestedState,
+		threshold.OneShot,
+		threshold.MaxTriggers,
+		threshold.TriggerCount,
+		threshold.StartsAt,
+		threshold.ExpiresAt,
+
+		threshold.DigestFrequency,
+		threshold.Critical,
+		threshold.Locale,
 
 		time.Now(),
 	)
@@ -181,139 +173,489 @@ func (r *AlertThresholdPostgres) Create(threshold *entity.AlertThreshold) error
 		return fmt.Errorf("erro ao salvar threshold no banco de dados: %w", err)
 	}
 
+	for i := range threshold.Rules {
+		rule := &threshold.Rules[i]
+		err = tx.QueryRow(
+			`INSERT INTO user_crypto_threshold_rules (threshold_id, kind, period, direction, value, enabled)
+			VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+			id, rule.Kind, rule.Period, rule.Direction, rule.Value, rule.Enabled,
+		).Scan(&rule.ID)
+		if err != nil {
+			return fmt.Errorf("erro ao salvar regra do threshold: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	threshold.ID = int64(id)
+
 	log.Printf("Threshold salvo com sucesso no banco de dados com ID: %d", id)
 	return nil
 }
 
+const thresholdColumns = `
+	id,
+	email,
+	crypto_symbol,
+	quote_symbol,
+
+	composite_rule,
+	composite_rule_enabled,
+
+	custom_expression,
+	custom_expression_enabled,
+
+	depeg_enabled,
+	depeg_peg_price,
+	depeg_band_bps,
+	depeg_sustained_scans,
+	depeg_consecutive_scans,
+
+	range_lookback_days,
+	new_high_enabled,
+	new_low_enabled,
+	drawdown_percent,
+	drawdown_enabled,
+
+	volatility_enabled,
+	volatility_short_window,
+	volatility_long_window,
+	volatility_multiple,
+	volatility_regime_active,
+
+	last_observed_price,
+	last_cross_direction,
+	last_crossed_at,
+
+	state,
+	one_shot,
+	max_triggers,
+	trigger_count,
+	starts_at,
+	expires_at,
+	snoozed_until,
+
+	digest_frequency,
+	last_digest_at,
+	critical,
+	locale`
+
 func (r *AlertThresholdPostgres) GetAllThresholds() ([]*entity.AlertThreshold, error) {
-	sql := `
-		SELECT 
-			email, 
-			crypto_symbol,
-			
-			threshold_up_1h_percent, 
-			threshold_up_1h_enabled, 
-			threshold_down_1h_percent, 
-			threshold_down_1h_enabled,
-			
-			threshold_up_24h_percent, 
-			threshold_up_24h_enabled, 
-			threshold_down_24h_percent, 
-			threshold_down_24h_enabled,
-			
-			threshold_up_7d_percent, 
-			threshold_up_7d_enabled, 
-			threshold_down_7d_percent, 
-			threshold_down_7d_enabled,
-			
-			threshold_up_30d_percent, 
-			threshold_up_30d_enabled, 
-			threshold_down_30d_percent, 
-			threshold_down_30d_enabled,
-			
-			threshold_up_60d_percent, 
-			threshold_up_60d_enabled, 
-			threshold_down_60d_percent, 
-			threshold_down_60d_enabled,
-			
-			threshold_up_90d_percent, 
-			threshold_up_90d_enabled, 
-			threshold_down_90d_percent, 
-			threshold_down_90d_enabled,
-			
-			target_price_up,
-			target_price_up_enabled,
-			target_price_down,
-			target_price_down_enabled
-		FROM user_crypto_thresholds
-		ORDER BY crypto_symbol, email
-	`
+	query := fmt.Sprintf(`SELECT %s FROM user_crypto_thresholds ORDER BY crypto_symbol, email`, thresholdColumns)
 
-	rows, err := r.db.Conn.Query(sql)
+	rows, err := r.db.Conn.Query(query)
 	if err != nil {
 		return nil, fmt.Errorf("erro ao buscar thresholds do banco de dados: %w", err)
 	}
 	defer rows.Close()
 
+	thresholds, err := scanThresholds(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadRules(thresholds); err != nil {
+		return nil, err
+	}
+
+	log.Printf("Carregados %d thresholds do banco de dados", len(thresholds))
+	return thresholds, nil
+}
+
+func (r *AlertThresholdPostgres) GetThresholdsByEmail(email string) ([]*entity.AlertThreshold, error) {
+	query := fmt.Sprintf(`SELECT %s FROM user_crypto_thresholds WHERE email = $1 ORDER BY crypto_symbol, id`, thresholdColumns)
+
+	rows, err := r.db.Conn.Query(query, email)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar thresholds do usuário: %w", err)
+	}
+	defer rows.Close()
+
+	thresholds, err := scanThresholds(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := r.loadRules(thresholds); err != nil {
+		return nil, err
+	}
+
+	return thresholds, nil
+}
+
+// loadRules attaches the rules of all given thresholds with a single query.
+func (r *AlertThresholdPostgres) loadRules(thresholds []*entity.AlertThreshold) error {
+	if len(thresholds) == 0 {
+		return nil
+	}
+
+	byID := make(map[int64]*entity.AlertThreshold, len(thresholds))
+	ids := make([]int64, 0, len(thresholds))
+	for _, threshold := range thresholds {
+		byID[threshold.ID] = threshold
+		ids = append(ids, threshold.ID)
+	}
+
+	rows, err := r.db.Conn.Query(
+		`SELECT id, threshold_id, kind, period, direction, value, enabled
+		FROM user_crypto_threshold_rules
+		WHERE threshold_id = ANY($1)
+		ORDER BY threshold_id, id`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao buscar regras dos thresholds: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var rule entity.AlertRule
+		var thresholdID int64
+
+		if err := rows.Scan(&rule.ID, &thresholdID, &rule.Kind, &rule.Period, &rule.Direction, &rule.Value, &rule.Enabled); err != nil {
+			return fmt.Errorf("erro ao fazer scan das regras: %w", err)
+		}
+
+		if threshold, ok := byID[thresholdID]; ok {
+			threshold.Rules = append(threshold.Rules, rule)
+		}
+	}
+
+	if err := rows.Err(); err != nil {
+		return fmt.Errorf("erro ao iterar sobre as regras: %w", err)
+	}
+
+	return nil
+}
+
+func scanThresholds(rows *sql.Rows) ([]*entity.AlertThreshold, error) {
 	var thresholds []*entity.AlertThreshold
 
 	for rows.Next() {
 		threshold := &entity.AlertThreshold{}
-		
-		var up1hPercent, down1hPercent *float64
-		var up24hPercent, down24hPercent *float64
-		var up7dPercent, down7dPercent *float64
-		var up30dPercent, down30dPercent *float64
-		var up60dPercent, down60dPercent *float64
-		var up90dPercent, down90dPercent *float64
-		var targetPriceUp, targetPriceDown *float64
+
+		var quoteSymbol, lastCrossDirection *string
+		var maxTriggers *int64
+		var compositeRule []byte
 
 		err := rows.Scan(
+			&threshold.ID,
 			&threshold.Email,
 			&threshold.CryptoSymbol,
-
-			&up1hPercent,
-			&threshold.ThresholdUp1hEnabled,
-			&down1hPercent,
-			&threshold.ThresholdDown1hEnabled,
-
-			&up24hPercent,
-			&threshold.ThresholdUp24hEnabled,
-			&down24hPercent,
-			&threshold.ThresholdDown24hEnabled,
-
-			&up7dPercent,
-			&threshold.ThresholdUp7dEnabled,
-			&down7dPercent,
-			&threshold.ThresholdDown7dEnabled,
-
-			&up30dPercent,
-			&threshold.ThresholdUp30dEnabled,
-			&down30dPercent,
-			&threshold.ThresholdDown30dEnabled,
-
-			&up60dPercent,
-			&threshold.ThresholdUp60dEnabled,
-			&down60dPercent,
-			&threshold.ThresholdDown60dEnabled,
-
-			&up90dPercent,
-			&threshold.ThresholdUp90dEnabled,
-			&down90dPercent,
-			&threshold.ThresholdDown90dEnabled,
-
-			&targetPriceUp,
-			&threshold.TargetPriceUpEnabled,
-			&targetPriceDown,
-			&threshold.TargetPriceDownEnabled,
+			&quoteSymbol,
+
+			&compositeRule,
+			&threshold.CompositeRuleEnabled,
+
+			&threshold.CustomExpression,
+			&threshold.CustomExpressionEnabled,
+
+			&threshold.DepegEnabled,
+			&threshold.DepegPegPrice,
+			&threshold.DepegBandBps,
+			&threshold.DepegSustainedScans,
+			&threshold.DepegConsecutiveScans,
+
+			&threshold.RangeLookbackDays,
+			&threshold.NewHighEnabled,
+			&threshold.NewLowEnabled,
+			&threshold.DrawdownPercent,
+			&threshold.DrawdownEnabled,
+
+			&threshold.VolatilityEnabled,
+			&threshold.VolatilityShortWindow,
+			&threshold.VolatilityLongWindow,
+			&threshold.VolatilityMultiple,
+			&threshold.VolatilityRegimeActive,
+
+			&threshold.LastObservedPrice,
+			&lastCrossDirection,
+			&threshold.LastCrossedAt,
+
+			&threshold.State,
+			&threshold.OneShot,
+			&maxTriggers,
+			&threshold.TriggerCount,
+			&threshold.StartsAt,
+			&threshold.ExpiresAt,
+			&threshold.SnoozedUntil,
+
+			&threshold.DigestFrequency,
+			&threshold.LastDigestAt,
+			&threshold.Critical,
+			&threshold.Locale,
 		)
 
 		if err != nil {
 			return nil, fmt.Errorf("erro ao fazer scan dos thresholds: %w", err)
 		}
 
-		threshold.ThresholdUp1hPercent = up1hPercent
-		threshold.ThresholdDown1hPercent = down1hPercent
-		threshold.ThresholdUp24hPercent = up24hPercent
-		threshold.ThresholdDown24hPercent = down24hPercent
-		threshold.ThresholdUp7dPercent = up7dPercent
-		threshold.ThresholdDown7dPercent = down7dPercent
-		threshold.ThresholdUp30dPercent = up30dPercent
-		threshold.ThresholdDown30dPercent = down30dPercent
-		threshold.ThresholdUp60dPercent = up60dPercent
-		threshold.ThresholdDown60dPercent = down60dPercent
-		threshold.ThresholdUp90dPercent = up90dPercent
-		threshold.ThresholdDown90dPercent = down90dPercent
-		threshold.TargetPriceUp = targetPriceUp
-		threshold.TargetPriceDown = targetPriceDown
+		if quoteSymbol != nil {
+			threshold.QuoteSymbol = *quoteSymbol
+		}
+		if lastCrossDirection != nil {
+			threshold.LastCrossDirection = *lastCrossDirection
+		}
+		if maxTriggers != nil {
+			value := int(*maxTriggers)
+			threshold.MaxTriggers = &value
+		}
+		if threshold.CompositeRule, err = unmarshalCompositeRule(compositeRule); err != nil {
+			return nil, err
+		}
 
 		thresholds = append(thresholds, threshold)
 	}
 
-	if err = rows.Err(); err != nil {
+	if err := rows.Err(); err != nil {
 		return nil, fmt.Errorf("erro ao iterar sobre os resultados: %w", err)
 	}
 
-	log.Printf("Carregados %d thresholds do banco de dados", len(thresholds))
 	return thresholds, nil
 }
+
+func (r *AlertThresholdPostgres) UpdatePriceObservation(id int64, price float64, crossDirection string) error {
+	if crossDirection == "" {
+		_, err := r.db.Conn.Exec(
+			`UPDATE user_crypto_thresholds SET last_observed_price = $1 WHERE id = $2`,
+			price, id,
+		)
+		if err != nil {
+			return fmt.Errorf("erro ao atualizar último preço observado: %w", err)
+		}
+		return nil
+	}
+
+	_, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds
+		SET last_observed_price = $1, last_cross_direction = $2, last_crossed_at = $3
+		WHERE id = $4`,
+		price, crossDirection, time.Now(), id,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar cruzamento de preço: %w", err)
+	}
+
+	return nil
+}
+
+// UpdateState only applies the transitions listed in
+// entity.AlertStateTransitions, so a triggered or expired alert is never
+// revived by a stale request.
+func (r *AlertThresholdPostgres) UpdateState(id int64, email string, state string) error {
+	result, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds SET state = $1 WHERE id = $2 AND email = $3 AND state = ANY($4)`,
+		state, id, email, pq.Array(entity.AlertStateTransitions[state]),
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar estado do threshold: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar atualização do threshold: %w", err)
+	}
+	if affected > 0 {
+		return nil
+	}
+
+	var current string
+	err = r.db.Conn.QueryRow(
+		`SELECT state FROM user_crypto_thresholds WHERE id = $1 AND email = $2`,
+		id, email,
+	).Scan(&current)
+	if errors.Is(err, sql.ErrNoRows) || current == entity.AlertStatePending {
+		return ErrThresholdNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("erro ao consultar estado do threshold: %w", err)
+	}
+
+	return fmt.Errorf("%w: %s para %s", ErrInvalidStateTransition, current, state)
+}
+
+// RecordTrigger stores the trigger count and the state a scan moved an active
+// threshold to. It fails with ErrInvalidStateTransition when the threshold
+// stopped being active since the scan read it, e.g. paused or deleted by the
+// user in the meantime.
+func (r *AlertThresholdPostgres) RecordTrigger(id int64, triggerCount int, state string) error {
+	result, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds SET trigger_count = $1, state = $2 WHERE id = $3 AND state = $4`,
+		triggerCount, state, id, entity.AlertStateActive,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar disparo do threshold: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar atualização do threshold: %w", err)
+	}
+	if affected == 0 {
+		return fmt.Errorf("%w: threshold %d não está mais ativo", ErrInvalidStateTransition, id)
+	}
+
+	return nil
+}
+
+func (r *AlertThresholdPostgres) UpdateDepegConsecutiveScans(id int64, consecutiveScans int) error {
+	_, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds SET depeg_consecutive_scans = $1 WHERE id = $2`,
+		consecutiveScans, id,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar contador de depeg: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AlertThresholdPostgres) UpdateVolatilityRegime(id int64, active bool) error {
+	_, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds SET volatility_regime_active = $1 WHERE id = $2`,
+		active, id,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar regime de volatilidade: %w", err)
+	}
+
+	return nil
+}
+
+func marshalCompositeRule(rule *entity.Condition) (interface{}, error) {
+	if rule == nil {
+		return nil, nil
+	}
+
+	data, err := json.Marshal(rule)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao serializar regra composta: %w", err)
+	}
+
+	return data, nil
+}
+
+func unmarshalCompositeRule(data []byte) (*entity.Condition, error) {
+	if len(data) == 0 {
+		return nil, nil
+	}
+
+	var rule entity.Condition
+	if err := json.Unmarshal(data, &rule); err != nil {
+		return nil, fmt.Errorf("erro ao desserializar regra composta: %w", err)
+	}
+
+	return &rule, nil
+}
+
+func (r *AlertThresholdPostgres) UpdateLastDigest(ids []int64, sentAt time.Time) error {
+	_, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds SET last_digest_at = $1 WHERE id = ANY($2)`,
+		sentAt, pq.Array(ids),
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar envio do resumo: %w", err)
+	}
+
+	return nil
+}
+
+func (r *AlertThresholdPostgres) Snooze(id int64, email string, until time.Time) error {
+	result, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds SET snoozed_until = $1 WHERE id = $2 AND email = $3`,
+		until, id, email,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao silenciar threshold: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar atualização do threshold: %w", err)
+	}
+	if affected == 0 {
+		return ErrThresholdNotFound
+	}
+
+	return nil
+}
+
+func (r *AlertThresholdPostgres) DisableRule(thresholdID int64, ruleID int64, email string) error {
+	result, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_threshold_rules AS rule SET enabled = FALSE
+		FROM user_crypto_thresholds AS threshold
+		WHERE rule.id = $1 AND rule.threshold_id = threshold.id AND threshold.id = $2 AND threshold.email = $3`,
+		ruleID, thresholdID, email,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao desativar regra: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("erro ao verificar atualização da regra: %w", err)
+	}
+	if affected == 0 {
+		return ErrThresholdNotFound
+	}
+
+	return nil
+}
+
+// PauseSymbol pauses every active threshold of the user for the symbol and
+// returns how many were paused.
+func (r *AlertThresholdPostgres) PauseSymbol(email string, symbol string) (int, error) {
+	result, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds SET state = $1 WHERE email = $2 AND crypto_symbol = $3 AND state = $4`,
+		entity.AlertStatePaused, email, symbol, entity.AlertStateActive,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao pausar thresholds da moeda: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao verificar atualização dos thresholds: %w", err)
+	}
+
+	return int(affected), nil
+}
+
+// ActivatePending moves the pending thresholds of a confirmed address to the
+// state they were created with, active unless the user asked otherwise.
+func (r *AlertThresholdPostgres) ActivatePending(email string) (int, error) {
+	result, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds SET state = COALESCE(requested_state, $1), requested_state = NULL
+		WHERE LOWER(email) = LOWER($2) AND state = $3`,
+		entity.AlertStateActive, email, entity.AlertStatePending,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao ativar thresholds pendentes: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao verificar atualização dos thresholds: %w", err)
+	}
+
+	return int(affected), nil
+}
+
+func (r *AlertThresholdPostgres) ExpirePending(createdBefore time.Time) (int, error) {
+	result, err := r.db.Conn.Exec(
+		`UPDATE user_crypto_thresholds SET state = $1 WHERE state = $2 AND created_at < $3`,
+		entity.AlertStateExpired, entity.AlertStatePending, createdBefore,
+	)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao expirar thresholds pendentes: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao verificar atualização dos thresholds: %w", err)
+	}
+
+	return int(affected), nil
+}
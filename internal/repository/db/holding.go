@@ -0,0 +1,203 @@
+package db
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"database/sql"
+	"fmt"
+	"log"
+	"time"
+)
+
+type HoldingRepository interface {
+	SaveHolding(holding *entity.Holding) error
+	GetAllHoldings() ([]*entity.Holding, error)
+	GetHoldingsByEmail(email string) ([]*entity.Holding, error)
+	UpdateHoldingObservation(id int64, price float64) error
+	SavePortfolioAlert(alert *entity.PortfolioAlert) error
+	GetPortfolioAlerts() ([]*entity.PortfolioAlert, error)
+	UpdatePortfolioObservation(email string, value float64) error
+}
+
+type HoldingPostgres struct {
+	db *pkg.DB
+}
+
+func NewHoldingRepository(db *pkg.DB) HoldingRepository {
+	return &HoldingPostgres{db: db}
+}
+
+// SaveHolding creates the position or replaces quantity, entry price and
+// thresholds of an existing one for the same email and symbol.
+func (r *HoldingPostgres) SaveHolding(holding *entity.Holding) error {
+	err := r.db.Conn.QueryRow(
+		`INSERT INTO user_holdings (
+			email, crypto_symbol, quantity, average_entry_price,
+			pnl_percent_up, pnl_percent_down, pnl_amount_up, pnl_amount_down,
+			locale, created_at, updated_at
+		) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $10)
+		ON CONFLICT (email, crypto_symbol) DO UPDATE SET
+			quantity = EXCLUDED.quantity,
+			average_entry_price = EXCLUDED.average_entry_price,
+			pnl_percent_up = EXCLUDED.pnl_percent_up,
+			pnl_percent_down = EXCLUDED.pnl_percent_down,
+			pnl_amount_up = EXCLUDED.pnl_amount_up,
+			pnl_amount_down = EXCLUDED.pnl_amount_down,
+			locale = EXCLUDED.locale,
+			updated_at = EXCLUDED.updated_at
+		RETURNING id`,
+		holding.Email,
+		holding.CryptoSymbol,
+		holding.Quantity,
+		holding.AverageEntryPrice,
+		holding.PnLPercentUp,
+		holding.PnLPercentDown,
+		holding.PnLAmountUp,
+		holding.PnLAmountDown,
+		holding.Locale,
+		time.Now(),
+	).Scan(&holding.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar posição no banco de dados: %w", err)
+	}
+
+	log.Printf("Posição salva com sucesso no banco de dados com ID: %d", holding.ID)
+	return nil
+}
+
+const holdingColumns = `
+	id,
+	email,
+	crypto_symbol,
+	quantity,
+	average_entry_price,
+	pnl_percent_up,
+	pnl_percent_down,
+	pnl_amount_up,
+	pnl_amount_down,
+	last_observed_price,
+	locale`
+
+func (r *HoldingPostgres) GetAllHoldings() ([]*entity.Holding, error) {
+	query := fmt.Sprintf(`SELECT %s FROM user_holdings ORDER BY email, crypto_symbol`, holdingColumns)
+
+	rows, err := r.db.Conn.Query(query)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar posições do banco de dados: %w", err)
+	}
+	defer rows.Close()
+
+	return scanHoldings(rows)
+}
+
+func (r *HoldingPostgres) GetHoldingsByEmail(email string) ([]*entity.Holding, error) {
+	query := fmt.Sprintf(`SELECT %s FROM user_holdings WHERE email = $1 ORDER BY crypto_symbol`, holdingColumns)
+
+	rows, err := r.db.Conn.Query(query, email)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar posições do usuário: %w", err)
+	}
+	defer rows.Close()
+
+	return scanHoldings(rows)
+}
+
+func scanHoldings(rows *sql.Rows) ([]*entity.Holding, error) {
+	var holdings []*entity.Holding
+
+	for rows.Next() {
+		holding := &entity.Holding{}
+
+		err := rows.Scan(
+			&holding.ID,
+			&holding.Email,
+			&holding.CryptoSymbol,
+			&holding.Quantity,
+			&holding.AverageEntryPrice,
+			&holding.PnLPercentUp,
+			&holding.PnLPercentDown,
+			&holding.PnLAmountUp,
+			&holding.PnLAmountDown,
+			&holding.LastObservedPrice,
+			&holding.Locale,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan das posições: %w", err)
+		}
+
+		holdings = append(holdings, holding)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as posições: %w", err)
+	}
+
+	return holdings, nil
+}
+
+func (r *HoldingPostgres) UpdateHoldingObservation(id int64, price float64) error {
+	_, err := r.db.Conn.Exec(
+		`UPDATE user_holdings SET last_observed_price = $1 WHERE id = $2`,
+		price, id,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar último preço observado da posição: %w", err)
+	}
+
+	return nil
+}
+
+func (r *HoldingPostgres) SavePortfolioAlert(alert *entity.PortfolioAlert) error {
+	_, err := r.db.Conn.Exec(
+		`INSERT INTO user_portfolio_alerts (email, value_above, value_below, locale, updated_at)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (email) DO UPDATE SET
+			value_above = EXCLUDED.value_above,
+			value_below = EXCLUDED.value_below,
+			locale = EXCLUDED.locale,
+			updated_at = EXCLUDED.updated_at`,
+		alert.Email, alert.ValueAbove, alert.ValueBelow, alert.Locale, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar alerta de portfólio: %w", err)
+	}
+
+	return nil
+}
+
+func (r *HoldingPostgres) GetPortfolioAlerts() ([]*entity.PortfolioAlert, error) {
+	rows, err := r.db.Conn.Query(
+		`SELECT email, value_above, value_below, last_observed_value, locale FROM user_portfolio_alerts ORDER BY email`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar alertas de portfólio: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*entity.PortfolioAlert
+	for rows.Next() {
+		alert := &entity.PortfolioAlert{}
+		if err := rows.Scan(&alert.Email, &alert.ValueAbove, &alert.ValueBelow, &alert.LastObservedValue, &alert.Locale); err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan dos alertas de portfólio: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os alertas de portfólio: %w", err)
+	}
+
+	return alerts, nil
+}
+
+func (r *HoldingPostgres) UpdatePortfolioObservation(email string, value float64) error {
+	_, err := r.db.Conn.Exec(
+		`UPDATE user_portfolio_alerts SET last_observed_value = $1 WHERE email = $2`,
+		value, email,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao atualizar último valor observado do portfólio: %w", err)
+	}
+
+	return nil
+}
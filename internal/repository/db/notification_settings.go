@@ -0,0 +1,128 @@
+package db
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type NotificationSettingsRepository interface {
+	Save(settings *entity.NotificationSettings) error
+	GetByEmail(email string) (*entity.NotificationSettings, error)
+	GetByEmails(emails []string) (map[string]*entity.NotificationSettings, error)
+	Enqueue(alert *entity.QueuedAlert) error
+	GetQueued() ([]*entity.QueuedAlert, error)
+}
+
+type NotificationSettingsPostgres struct {
+	db *pkg.DB
+}
+
+func NewNotificationSettingsRepository(db *pkg.DB) NotificationSettingsRepository {
+	return &NotificationSettingsPostgres{db: db}
+}
+
+func (r *NotificationSettingsPostgres) Save(settings *entity.NotificationSettings) error {
+	_, err := r.db.Conn.Exec(
+		`INSERT INTO user_notification_settings (email, time_zone, quiet_hours_start, quiet_hours_end, summarize_queued, updated_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		ON CONFLICT (email) DO UPDATE SET
+			time_zone = EXCLUDED.time_zone,
+			quiet_hours_start = EXCLUDED.quiet_hours_start,
+			quiet_hours_end = EXCLUDED.quiet_hours_end,
+			summarize_queued = EXCLUDED.summarize_queued,
+			updated_at = EXCLUDED.updated_at`,
+		settings.Email,
+		settings.TimeZone,
+		settings.QuietHoursStart,
+		settings.QuietHoursEnd,
+		settings.SummarizeQueued,
+		time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao salvar preferências de notificação: %w", err)
+	}
+
+	return nil
+}
+
+// GetByEmail returns nil when the user never saved any preference.
+func (r *NotificationSettingsPostgres) GetByEmail(email string) (*entity.NotificationSettings, error) {
+	settings, err := r.GetByEmails([]string{email})
+	if err != nil {
+		return nil, err
+	}
+	return settings[email], nil
+}
+
+func (r *NotificationSettingsPostgres) GetByEmails(emails []string) (map[string]*entity.NotificationSettings, error) {
+	rows, err := r.db.Conn.Query(
+		`SELECT email, time_zone, quiet_hours_start, quiet_hours_end, summarize_queued
+		FROM user_notification_settings
+		WHERE email = ANY($1)`,
+		pq.Array(emails),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar preferências de notificação: %w", err)
+	}
+	defer rows.Close()
+
+	settings := make(map[string]*entity.NotificationSettings)
+	for rows.Next() {
+		s := &entity.NotificationSettings{}
+		if err := rows.Scan(&s.Email, &s.TimeZone, &s.QuietHoursStart, &s.QuietHoursEnd, &s.SummarizeQueued); err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan das preferências de notificação: %w", err)
+		}
+		settings[s.Email] = s
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as preferências de notificação: %w", err)
+	}
+
+	return settings, nil
+}
+
+func (r *NotificationSettingsPostgres) Enqueue(alert *entity.QueuedAlert) error {
+	if alert.CreatedAt.IsZero() {
+		alert.CreatedAt = time.Now()
+	}
+
+	err := r.db.Conn.QueryRow(
+		`INSERT INTO queued_alerts (email, subject, body, text_body, locale, created_at) VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		alert.Email, alert.Subject, alert.Body, alert.TextBody, alert.Locale, alert.CreatedAt,
+	).Scan(&alert.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao enfileirar alerta: %w", err)
+	}
+
+	return nil
+}
+
+func (r *NotificationSettingsPostgres) GetQueued() ([]*entity.QueuedAlert, error) {
+	rows, err := r.db.Conn.Query(
+		`SELECT id, email, subject, body, text_body, locale, created_at FROM queued_alerts ORDER BY email, created_at, id`,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar alertas enfileirados: %w", err)
+	}
+	defer rows.Close()
+
+	var alerts []*entity.QueuedAlert
+	for rows.Next() {
+		alert := &entity.QueuedAlert{}
+		if err := rows.Scan(&alert.ID, &alert.Email, &alert.Subject, &alert.Body, &alert.TextBody, &alert.Locale, &alert.CreatedAt); err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan dos alertas enfileirados: %w", err)
+		}
+		alerts = append(alerts, alert)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os alertas enfileirados: %w", err)
+	}
+
+	return alerts, nil
+}
@@ -0,0 +1,286 @@
+package db
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"fmt"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type OutboxRepository interface {
+	Enqueue(message *entity.OutboxMessage, history []*entity.AlertHistory) error
+	ReleaseQueued(messages []*entity.OutboxMessage, queuedIDs []int64) error
+	Claim(now time.Time, lockedUntil time.Time, limit int) ([]*entity.OutboxMessage, error)
+	MarkSent(id int64, sentAt time.Time) error
+	MarkFailed(id int64, attempts int, nextAttemptAt time.Time, lastError string) error
+	MarkDead(id int64, attempts int, lastError string) error
+	GetDead(limit int) ([]*entity.OutboxMessage, error)
+	Redrive(ids []int64, now time.Time) (int, error)
+}
+
+type OutboxPostgres struct {
+	db *pkg.DB
+}
+
+func NewOutboxRepository(db *pkg.DB) OutboxRepository {
+	return &OutboxPostgres{db: db}
+}
+
+// Enqueue stores the message together with the history of the alerts it
+// carries, so an alert is never recorded without its email or vice versa.
+func (r *OutboxPostgres) Enqueue(message *entity.OutboxMessage, history []*entity.AlertHistory) error {
+	tx, err := r.db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if err := insertOutboxMessage(tx, message); err != nil {
+		return err
+	}
+
+	for _, entry := range history {
+		if err := insertAlertHistory(tx, entry); err != nil {
+			return err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return nil
+}
+
+// ReleaseQueued stores the messages built from alerts held back by quiet
+// hours and removes those alerts from the queue in the same transaction, so
+// they are neither lost nor sent twice.
+func (r *OutboxPostgres) ReleaseQueued(messages []*entity.OutboxMessage, queuedIDs []int64) error {
+	tx, err := r.db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, message := range messages {
+		if err := insertOutboxMessage(tx, message); err != nil {
+			return err
+		}
+	}
+
+	if _, err := tx.Exec(`DELETE FROM queued_alerts WHERE id = ANY($1)`, pq.Array(queuedIDs)); err != nil {
+		return fmt.Errorf("erro ao remover alertas enfileirados: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return nil
+}
+
+func insertOutboxMessage(querier rowQuerier, message *entity.OutboxMessage) error {
+	if message.CreatedAt.IsZero() {
+		message.CreatedAt = time.Now()
+	}
+	if message.NextAttemptAt.IsZero() {
+		message.NextAttemptAt = message.CreatedAt
+	}
+	message.Status = entity.OutboxStatusPending
+
+	err := querier.QueryRow(
+		`INSERT INTO outbox_messages (email, subject, body, text_body, status, next_attempt_at, created_at)
+		VALUES ($1, $2, $3, $4, $5, $6) RETURNING id`,
+		message.Email, message.Subject, message.Body, message.TextBody, message.Status, message.NextAttemptAt, message.CreatedAt,
+	).Scan(&message.ID)
+	if err != nil {
+		return fmt.Errorf("erro ao enfileirar e-mail: %w", err)
+	}
+
+	return nil
+}
+
+const outboxColumns = `id, email, subject, body, text_body, status, attempts, next_attempt_at, last_error, created_at, sent_at, redrive_count`
+
+// Claim marks the due messages as being sent until lockedUntil and returns
+// them. Rows locked by a concurrent claim are skipped, so no two runs, even
+// on different instances, get the same message. Messages whose claim has
+// lapsed are due again.
+func (r *OutboxPostgres) Claim(now time.Time, lockedUntil time.Time, limit int) ([]*entity.OutboxMessage, error) {
+	return r.query(
+		fmt.Sprintf(`UPDATE outbox_messages SET status = $1, locked_until = $2
+		WHERE id IN (
+			SELECT id FROM outbox_messages
+			WHERE (status = $3 AND next_attempt_at <= $4) OR (status = $1 AND locked_until <= $4)
+			ORDER BY next_attempt_at, id
+			LIMIT $5
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING %s`, outboxColumns),
+		entity.OutboxStatusSending, lockedUntil, entity.OutboxStatusPending, now, limit,
+	)
+}
+
+// GetDead returns the dead letters with every failure recorded for them,
+// including those from before earlier redrives.
+func (r *OutboxPostgres) GetDead(limit int) ([]*entity.OutboxMessage, error) {
+	messages, err := r.query(
+		fmt.Sprintf(`SELECT %s FROM outbox_messages WHERE status = $1 ORDER BY created_at DESC, id DESC LIMIT $2`, outboxColumns),
+		entity.OutboxStatusDead, limit,
+	)
+	if err != nil || len(messages) == 0 {
+		return messages, err
+	}
+
+	ids := make([]int64, len(messages))
+	byID := make(map[int64]*entity.OutboxMessage, len(messages))
+	for i, message := range messages {
+		ids[i] = message.ID
+		byID[message.ID] = message
+	}
+
+	rows, err := r.db.Conn.Query(
+		`SELECT message_id, attempt, error, failed_at FROM outbox_failures WHERE message_id = ANY($1) ORDER BY failed_at, id`,
+		pq.Array(ids),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar falhas de envio: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var messageID int64
+		var failure entity.OutboxFailure
+		if err := rows.Scan(&messageID, &failure.Attempt, &failure.Error, &failure.FailedAt); err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan das falhas de envio: %w", err)
+		}
+		byID[messageID].Failures = append(byID[messageID].Failures, failure)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre as falhas de envio: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *OutboxPostgres) query(query string, args ...interface{}) ([]*entity.OutboxMessage, error) {
+	rows, err := r.db.Conn.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("erro ao buscar e-mails da fila: %w", err)
+	}
+	defer rows.Close()
+
+	var messages []*entity.OutboxMessage
+	for rows.Next() {
+		message := &entity.OutboxMessage{}
+		err := rows.Scan(
+			&message.ID,
+			&message.Email,
+			&message.Subject,
+			&message.Body,
+			&message.TextBody,
+			&message.Status,
+			&message.Attempts,
+			&message.NextAttemptAt,
+			&message.LastError,
+			&message.CreatedAt,
+			&message.SentAt,
+			&message.RedriveCount,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("erro ao fazer scan dos e-mails da fila: %w", err)
+		}
+		messages = append(messages, message)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("erro ao iterar sobre os e-mails da fila: %w", err)
+	}
+
+	return messages, nil
+}
+
+func (r *OutboxPostgres) MarkSent(id int64, sentAt time.Time) error {
+	_, err := r.db.Conn.Exec(
+		`UPDATE outbox_messages SET status = $1, attempts = attempts + 1, sent_at = $2, last_error = '', locked_until = NULL WHERE id = $3`,
+		entity.OutboxStatusSent, sentAt, id,
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao marcar e-mail como enviado: %w", err)
+	}
+
+	return nil
+}
+
+// MarkFailed releases the claim so the message is retried at
+// nextAttemptAt.
+func (r *OutboxPostgres) MarkFailed(id int64, attempts int, nextAttemptAt time.Time, lastError string) error {
+	return r.recordFailure(
+		`UPDATE outbox_messages SET status = $1, attempts = $2, next_attempt_at = $3, last_error = $4, locked_until = NULL WHERE id = $5`,
+		id, attempts, lastError,
+		entity.OutboxStatusPending, attempts, nextAttemptAt, lastError, id,
+	)
+}
+
+func (r *OutboxPostgres) MarkDead(id int64, attempts int, lastError string) error {
+	return r.recordFailure(
+		`UPDATE outbox_messages SET status = $1, attempts = $2, last_error = $3, locked_until = NULL WHERE id = $4`,
+		id, attempts, lastError,
+		entity.OutboxStatusDead, attempts, lastError, id,
+	)
+}
+
+// recordFailure runs the status update together with the insert into
+// outbox_failures, which outlives the attempt count reset by Redrive.
+func (r *OutboxPostgres) recordFailure(update string, id int64, attempt int, lastError string, args ...interface{}) error {
+	tx, err := r.db.Conn.Begin()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar transação: %w", err)
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(update, args...); err != nil {
+		return fmt.Errorf("erro ao registrar falha de envio: %w", err)
+	}
+
+	_, err = tx.Exec(
+		`INSERT INTO outbox_failures (message_id, attempt, error, failed_at) VALUES ($1, $2, $3, $4)`,
+		id, attempt, lastError, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao registrar falha de envio: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("erro ao confirmar transação: %w", err)
+	}
+
+	return nil
+}
+
+// Redrive puts dead messages back in the queue with a fresh attempt count,
+// the earlier failures staying in outbox_failures. An empty list re-drives
+// every dead message.
+func (r *OutboxPostgres) Redrive(ids []int64, now time.Time) (int, error) {
+	query := `UPDATE outbox_messages SET status = $1, attempts = 0, redrive_count = redrive_count + 1, next_attempt_at = $2 WHERE status = $3`
+	args := []interface{}{entity.OutboxStatusPending, now, entity.OutboxStatusDead}
+	if len(ids) > 0 {
+		query += ` AND id = ANY($4)`
+		args = append(args, pq.Array(ids))
+	}
+
+	result, err := r.db.Conn.Exec(query, args...)
+	if err != nil {
+		return 0, fmt.Errorf("erro ao reenfileirar e-mails: %w", err)
+	}
+
+	affected, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("erro ao verificar e-mails reenfileirados: %w", err)
+	}
+
+	return int(affected), nil
+}
@@ -0,0 +1,56 @@
+package db
+
+import (
+	"crypto-alerts/internal/pkg"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SuppressionReasonUnsubscribe marks addresses removed by the recipient
+// through the List-Unsubscribe link.
+const SuppressionReasonUnsubscribe = "unsubscribe"
+
+type SuppressionRepository interface {
+	Suppress(email string, reason string) error
+	IsSuppressed(email string) (bool, error)
+}
+
+type SuppressionPostgres struct {
+	db *pkg.DB
+}
+
+func NewSuppressionRepository(db *pkg.DB) SuppressionRepository {
+	return &SuppressionPostgres{db: db}
+}
+
+func (r *SuppressionPostgres) Suppress(email string, reason string) error {
+	_, err := r.db.Conn.Exec(
+		`INSERT INTO email_suppressions (email, reason, created_at) VALUES ($1, $2, $3)
+		ON CONFLICT (email) DO NOTHING`,
+		strings.ToLower(email), reason, time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao adicionar e-mail à lista de supressão: %w", err)
+	}
+
+	return nil
+}
+
+func (r *SuppressionPostgres) IsSuppressed(email string) (bool, error) {
+	var found int
+	err := r.db.Conn.QueryRow(
+		`SELECT 1 FROM email_suppressions WHERE email = $1`,
+		strings.ToLower(email),
+	).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("erro ao consultar lista de supressão: %w", err)
+	}
+
+	return true, nil
+}
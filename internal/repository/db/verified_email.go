@@ -0,0 +1,52 @@
+package db
+
+import (
+	"crypto-alerts/internal/pkg"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type VerifiedEmailRepository interface {
+	MarkVerified(email string) error
+	IsVerified(email string) (bool, error)
+}
+
+type VerifiedEmailPostgres struct {
+	db *pkg.DB
+}
+
+func NewVerifiedEmailRepository(db *pkg.DB) VerifiedEmailRepository {
+	return &VerifiedEmailPostgres{db: db}
+}
+
+func (r *VerifiedEmailPostgres) MarkVerified(email string) error {
+	_, err := r.db.Conn.Exec(
+		`INSERT INTO verified_emails (email, verified_at) VALUES ($1, $2)
+		ON CONFLICT (email) DO NOTHING`,
+		strings.ToLower(email), time.Now(),
+	)
+	if err != nil {
+		return fmt.Errorf("erro ao confirmar e-mail: %w", err)
+	}
+
+	return nil
+}
+
+func (r *VerifiedEmailPostgres) IsVerified(email string) (bool, error) {
+	var found int
+	err := r.db.Conn.QueryRow(
+		`SELECT 1 FROM verified_emails WHERE email = $1`,
+		strings.ToLower(email),
+	).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("erro ao consultar e-mails confirmados: %w", err)
+	}
+
+	return true, nil
+}
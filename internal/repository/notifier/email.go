@@ -2,47 +2,78 @@ package notifier
 
 import (
 	"crypto-alerts/internal/config"
+	"crypto-alerts/internal/pkg"
 	"fmt"
-	"net/smtp"
+	"log"
+	"net/mail"
+	"time"
 )
 
 type Notifier interface {
-	SendEmailAlert(to string, subject string, message string) error
+	SendEmailAlert(to string, subject string, body pkg.EmailBody) error
+}
+
+// SuppressionList tells whether an address asked not to receive any email.
+type SuppressionList interface {
+	IsSuppressed(email string) (bool, error)
 }
 
 type emailNotifier struct {
-	smtpConfig *config.SMTPConfig
+	smtpConfig   *config.SMTPConfig
+	suppressions SuppressionList
+	actionSigner *pkg.ActionSigner
+	pool         *smtpPool
 }
 
-func NewEmailNotifier(cfg *config.SMTPConfig) Notifier {
+func NewEmailNotifier(cfg *config.SMTPConfig, suppressions SuppressionList, actionSigner *pkg.ActionSigner) Notifier {
 	return &emailNotifier{
-		smtpConfig: cfg,
+		smtpConfig:   cfg,
+		suppressions: suppressions,
+		actionSigner: actionSigner,
+		pool:         newSMTPPool(cfg),
 	}
 }
 
-func (e *emailNotifier) SendEmailAlert(to string, subject string, message string) error {
+// SendEmailAlert skips suppressed recipients without an error, so callers
+// treat them as delivered and do not retry.
+func (e *emailNotifier) SendEmailAlert(to string, subject string, body pkg.EmailBody) error {
 	if e.smtpConfig == nil {
 		return fmt.Errorf("SMTP config not initialized")
 	}
-	smtpAddr := fmt.Sprintf("%s:%d", e.smtpConfig.Host, e.smtpConfig.Port)
 
-	headers := make(map[string]string)
-	headers["From"] = e.smtpConfig.Username
-	headers["To"] = to
-	headers["Subject"] = subject
-	headers["MIME-Version"] = "1.0"
-	headers["Content-Type"] = "text/html; charset=\"utf-8\""
+	suppressed, err := e.suppressions.IsSuppressed(to)
+	if err != nil {
+		return err
+	}
+	if suppressed {
+		log.Printf("Skipping email to %s: address is on the suppression list", to)
+		return nil
+	}
 
-	body := ""
-	for key, value := range headers {
-		body += fmt.Sprintf("%s: %s\r\n", key, value)
+	now := time.Now()
+	mailMessage := &pkg.MailMessage{
+		From:     mail.Address{Name: e.smtpConfig.FromName, Address: e.smtpConfig.From},
+		To:       to,
+		Subject:  subject,
+		HTMLBody: body.HTML,
+		TextBody: body.Text,
+		Date:     now,
 	}
-	body += "\r\n" + message
 
-	auth := smtp.PlainAuth("", e.smtpConfig.Username, e.smtpConfig.Password, e.smtpConfig.Host)
+	// RFC 8058 one-click unsubscribe
+	if unsubscribeURL := e.actionSigner.UnsubscribeURL(to, now); unsubscribeURL != "" {
+		mailMessage.Headers = map[string]string{
+			"List-Unsubscribe":      fmt.Sprintf("<%s>", unsubscribeURL),
+			"List-Unsubscribe-Post": "List-Unsubscribe=One-Click",
+		}
+	}
 
-	err := smtp.SendMail(smtpAddr, auth, e.smtpConfig.Username, []string{to}, []byte(body))
+	data, err := mailMessage.Bytes()
 	if err != nil {
+		return err
+	}
+
+	if err := e.pool.send(e.smtpConfig.From, to, data); err != nil {
 		return fmt.Errorf("erro ao enviar e-mail de alerta: %w", err)
 	}
 
@@ -0,0 +1,211 @@
+package notifier
+
+import (
+	"crypto-alerts/internal/config"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/smtp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	smtpDialTimeout = 10 * time.Second
+	// smtpIdleTimeout keeps the connection open between the emails of a
+	// scan and closes it once the scan is over.
+	smtpIdleTimeout = 30 * time.Second
+)
+
+// smtpPool holds a single SMTP connection reused by consecutive sends.
+type smtpPool struct {
+	cfg *config.SMTPConfig
+
+	mu        sync.Mutex
+	client    *smtp.Client
+	idleTimer *time.Timer
+}
+
+func newSMTPPool(cfg *config.SMTPConfig) *smtpPool {
+	return &smtpPool{cfg: cfg}
+}
+
+func (p *smtpPool) send(from string, to string, message []byte) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.client != nil && p.client.Noop() != nil {
+		p.closeLocked()
+	}
+	if p.client == nil {
+		client, err := p.dial()
+		if err != nil {
+			return err
+		}
+		p.client = client
+	}
+
+	if err := deliver(p.client, from, to, message); err != nil {
+		p.closeLocked()
+		return err
+	}
+
+	if p.idleTimer == nil {
+		p.idleTimer = time.AfterFunc(smtpIdleTimeout, p.close)
+	} else {
+		p.idleTimer.Reset(smtpIdleTimeout)
+	}
+
+	return nil
+}
+
+func (p *smtpPool) close() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.closeLocked()
+}
+
+func (p *smtpPool) closeLocked() {
+	if p.client == nil {
+		return
+	}
+	if err := p.client.Quit(); err != nil {
+		p.client.Close()
+	}
+	p.client = nil
+}
+
+func (p *smtpPool) dial() (*smtp.Client, error) {
+	addr := net.JoinHostPort(p.cfg.Host, strconv.Itoa(p.cfg.Port))
+	tlsConfig := &tls.Config{ServerName: p.cfg.Host}
+
+	var conn net.Conn
+	var err error
+	if p.cfg.TLSMode == config.SMTPTLSImplicit {
+		conn, err = tls.DialWithDialer(&net.Dialer{Timeout: smtpDialTimeout}, "tcp", addr, tlsConfig)
+	} else {
+		conn, err = net.DialTimeout("tcp", addr, smtpDialTimeout)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("erro ao conectar ao servidor SMTP: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, p.cfg.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("erro ao iniciar sessão SMTP: %w", err)
+	}
+
+	if err := p.secure(client, tlsConfig); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	if err := p.authenticate(client); err != nil {
+		client.Close()
+		return nil, err
+	}
+
+	return client, nil
+}
+
+func (p *smtpPool) secure(client *smtp.Client, tlsConfig *tls.Config) error {
+	if p.cfg.TLSMode != config.SMTPTLSStartTLS && p.cfg.TLSMode != config.SMTPTLSOpportunistic {
+		return nil
+	}
+
+	if ok, _ := client.Extension("STARTTLS"); !ok {
+		if p.cfg.TLSMode == config.SMTPTLSStartTLS {
+			return fmt.Errorf("servidor SMTP não oferece STARTTLS")
+		}
+		return nil
+	}
+
+	if err := client.StartTLS(tlsConfig); err != nil {
+		return fmt.Errorf("erro ao iniciar STARTTLS: %w", err)
+	}
+
+	return nil
+}
+
+func (p *smtpPool) authenticate(client *smtp.Client) error {
+	var auth smtp.Auth
+	switch p.cfg.Auth {
+	case config.SMTPAuthNone:
+		return nil
+	case config.SMTPAuthLogin:
+		auth = &loginAuth{username: p.cfg.Username, password: p.cfg.Password, host: p.cfg.Host}
+	default:
+		auth = smtp.PlainAuth("", p.cfg.Username, p.cfg.Password, p.cfg.Host)
+	}
+
+	if err := client.Auth(auth); err != nil {
+		return fmt.Errorf("erro ao autenticar no servidor SMTP: %w", err)
+	}
+
+	return nil
+}
+
+func deliver(client *smtp.Client, from string, to string, message []byte) error {
+	if err := client.Mail(from); err != nil {
+		return fmt.Errorf("erro ao definir remetente: %w", err)
+	}
+	if err := client.Rcpt(to); err != nil {
+		return fmt.Errorf("erro ao definir destinatário: %w", err)
+	}
+
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("erro ao iniciar envio da mensagem: %w", err)
+	}
+	if _, err := writer.Write(message); err != nil {
+		writer.Close()
+		return fmt.Errorf("erro ao enviar mensagem: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("erro ao finalizar mensagem: %w", err)
+	}
+
+	return nil
+}
+
+// loginAuth implements the LOGIN mechanism, which net/smtp lacks but some
+// providers still require. Like smtp.PlainAuth it refuses to send the
+// password over an unencrypted connection to a remote host.
+type loginAuth struct {
+	username string
+	password string
+	host     string
+}
+
+func (a *loginAuth) Start(server *smtp.ServerInfo) (string, []byte, error) {
+	if !server.TLS && !isLocalhost(server.Name) {
+		return "", nil, errors.New("unencrypted connection")
+	}
+	if server.Name != a.host {
+		return "", nil, errors.New("wrong host name")
+	}
+	return "LOGIN", nil, nil
+}
+
+func (a *loginAuth) Next(fromServer []byte, more bool) ([]byte, error) {
+	if !more {
+		return nil, nil
+	}
+
+	prompt := strings.ToLower(string(fromServer))
+	switch {
+	case strings.Contains(prompt, "username"):
+		return []byte(a.username), nil
+	case strings.Contains(prompt, "password"):
+		return []byte(a.password), nil
+	}
+	return nil, fmt.Errorf("unexpected LOGIN prompt %q", fromServer)
+}
+
+func isLocalhost(name string) bool {
+	return name == "localhost" || name == "127.0.0.1" || name == "::1"
+}
@@ -0,0 +1,342 @@
+package notifier
+
+import (
+	"bufio"
+	"crypto-alerts/internal/config"
+	"crypto-alerts/internal/pkg"
+	"encoding/base64"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeSMTPServer is a local stand-in for an SMTP relay, accepting any
+// credentials and recording the envelope and data of every message.
+type fakeSMTPServer struct {
+	listener net.Listener
+
+	mu          sync.Mutex
+	connections int
+	auths       []string
+	messages    []fakeSMTPMessage
+}
+
+type fakeSMTPMessage struct {
+	from string
+	to   []string
+	data string
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	server := &fakeSMTPServer{listener: listener}
+	t.Cleanup(func() { listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			server.mu.Lock()
+			server.connections++
+			server.mu.Unlock()
+			go server.serve(conn)
+		}
+	}()
+
+	return server
+}
+
+func (s *fakeSMTPServer) config(auth string) *config.SMTPConfig {
+	addr := s.listener.Addr().(*net.TCPAddr)
+	return &config.SMTPConfig{
+		Host:     "127.0.0.1",
+		Port:     addr.Port,
+		Username: "alerts",
+		Password: "secret",
+		TLSMode:  config.SMTPTLSOpportunistic,
+		Auth:     auth,
+		From:     "alerts@example.com",
+		FromName: "Crypto Alerts",
+	}
+}
+
+func (s *fakeSMTPServer) serve(conn net.Conn) {
+	defer conn.Close()
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { io.WriteString(conn, line+"\r\n") }
+	readLine := func() (string, bool) {
+		line, err := reader.ReadString('\n')
+		return strings.TrimRight(line, "\r\n"), err == nil
+	}
+
+	reply("220 localhost ESMTP fake")
+	var message fakeSMTPMessage
+	for {
+		line, ok := readLine()
+		if !ok {
+			return
+		}
+		verb := strings.ToUpper(strings.SplitN(line, " ", 2)[0])
+
+		switch verb {
+		case "EHLO", "HELO":
+			reply("250-localhost")
+			reply("250-8BITMIME")
+			reply("250 AUTH PLAIN LOGIN")
+		case "AUTH":
+			fields := strings.Fields(line)
+			mechanism := strings.ToUpper(fields[1])
+			if mechanism == "LOGIN" {
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Username:")))
+				username, _ := readLine()
+				reply("334 " + base64.StdEncoding.EncodeToString([]byte("Password:")))
+				password, _ := readLine()
+				decodedUser, _ := base64.StdEncoding.DecodeString(username)
+				decodedPassword, _ := base64.StdEncoding.DecodeString(password)
+				mechanism += " " + string(decodedUser) + ":" + string(decodedPassword)
+			} else if len(fields) > 2 {
+				decoded, _ := base64.StdEncoding.DecodeString(fields[2])
+				mechanism += " " + strings.ReplaceAll(strings.TrimPrefix(string(decoded), "\x00"), "\x00", ":")
+			}
+			s.mu.Lock()
+			s.auths = append(s.auths, mechanism)
+			s.mu.Unlock()
+			reply("235 2.7.0 Authentication successful")
+		case "MAIL":
+			message = fakeSMTPMessage{from: addressOf(line)}
+			reply("250 OK")
+		case "RCPT":
+			message.to = append(message.to, addressOf(line))
+			reply("250 OK")
+		case "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				dataLine, ok := readLine()
+				if !ok {
+					return
+				}
+				if dataLine == "." {
+					break
+				}
+				data.WriteString(strings.TrimPrefix(dataLine, ".") + "\r\n")
+			}
+			message.data = data.String()
+			s.mu.Lock()
+			s.messages = append(s.messages, message)
+			s.mu.Unlock()
+			reply("250 OK queued")
+		case "NOOP", "RSET":
+			reply("250 OK")
+		case "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+func (s *fakeSMTPServer) snapshot() (int, []string, []fakeSMTPMessage) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.connections, append([]string(nil), s.auths...), append([]fakeSMTPMessage(nil), s.messages...)
+}
+
+func addressOf(command string) string {
+	start, end := strings.Index(command, "<"), strings.Index(command, ">")
+	if start < 0 || end < start {
+		return ""
+	}
+	return command[start+1 : end]
+}
+
+type fakeSuppressionList map[string]bool
+
+func (l fakeSuppressionList) IsSuppressed(email string) (bool, error) {
+	return l[email], nil
+}
+
+func TestSendEmailAlertMessageStructure(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	signer := pkg.NewActionSigner("https://alerts.example.com", "secret")
+	notifier := NewEmailNotifier(server.config(config.SMTPAuthPlain), fakeSuppressionList{}, signer)
+
+	subject := "🚀 BTC subiu 5,00% - Preço atual US$ 65.000,00"
+	body := pkg.EmailBody{
+		HTML: "<html><body><p>Olá, o preço do <strong>BTC</strong> subiu!</p></body></html>",
+		Text: "Olá, o preço do BTC subiu para US$ 65.000,00!\n",
+	}
+	before := time.Now().Add(-time.Second)
+	if err := notifier.SendEmailAlert("user@example.com", subject, body); err != nil {
+		t.Fatalf("SendEmailAlert: %v", err)
+	}
+
+	_, auths, messages := server.snapshot()
+	if len(auths) != 1 || auths[0] != "PLAIN alerts:secret" {
+		t.Fatalf("auth = %v, want PLAIN with the configured credentials", auths)
+	}
+	if len(messages) != 1 {
+		t.Fatalf("got %d messages, want 1", len(messages))
+	}
+	sent := messages[0]
+	if sent.from != "alerts@example.com" || len(sent.to) != 1 || sent.to[0] != "user@example.com" {
+		t.Fatalf("envelope from %q to %v", sent.from, sent.to)
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(sent.data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	header := message.Header
+
+	rawSubject := header.Get("Subject")
+	if !strings.HasPrefix(rawSubject, "=?utf-8?q?") {
+		t.Errorf("Subject %q is not RFC 2047 encoded", rawSubject)
+	}
+	decodedSubject, err := new(mime.WordDecoder).DecodeHeader(rawSubject)
+	if err != nil || decodedSubject != subject {
+		t.Errorf("decoded Subject = %q (%v), want %q", decodedSubject, err, subject)
+	}
+
+	from, err := header.AddressList("From")
+	if err != nil || len(from) != 1 || from[0].Address != "alerts@example.com" || from[0].Name != "Crypto Alerts" {
+		t.Errorf("From = %v (%v)", from, err)
+	}
+
+	date, err := header.Date()
+	if err != nil || date.Before(before) || date.After(time.Now().Add(time.Second)) {
+		t.Errorf("Date = %v (%v), want the send time", date, err)
+	}
+
+	messageID := header.Get("Message-ID")
+	if !strings.HasPrefix(messageID, "<") || !strings.HasSuffix(messageID, "@example.com>") {
+		t.Errorf("Message-ID = %q, want <...@example.com>", messageID)
+	}
+
+	unsubscribe := header.Get("List-Unsubscribe")
+	if !strings.HasPrefix(unsubscribe, "<https://alerts.example.com"+pkg.UnsubscribePath+"?token=") || !strings.HasSuffix(unsubscribe, ">") {
+		t.Errorf("List-Unsubscribe = %q", unsubscribe)
+	}
+	if got := header.Get("List-Unsubscribe-Post"); got != "List-Unsubscribe=One-Click" {
+		t.Errorf("List-Unsubscribe-Post = %q", got)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(header.Get("Content-Type"))
+	if err != nil || mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q (%v), want multipart/alternative", header.Get("Content-Type"), err)
+	}
+
+	reader := multipart.NewReader(message.Body, params["boundary"])
+	var parts []string
+	var contents []string
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		decoded, err := io.ReadAll(part)
+		if err != nil {
+			t.Fatalf("reading part: %v", err)
+		}
+		parts = append(parts, part.Header.Get("Content-Type"))
+		contents = append(contents, string(decoded))
+	}
+
+	// multipart.Reader decodes quoted-printable parts and drops their
+	// Content-Transfer-Encoding, so the encoding is checked on the raw data
+	if count := strings.Count(sent.data, "Content-Transfer-Encoding: quoted-printable"); count != 2 {
+		t.Errorf("%d quoted-printable parts, want 2", count)
+	}
+	if len(parts) != 2 || parts[0] != "text/plain; charset=utf-8" || parts[1] != "text/html; charset=utf-8" {
+		t.Fatalf("parts = %v, want text/plain then text/html", parts)
+	}
+	if !strings.Contains(contents[0], "Olá, o preço do BTC subiu para US$ 65.000,00!") {
+		t.Errorf("text part = %q, want the rendered text body", contents[0])
+	}
+	if !strings.Contains(contents[1], "<strong>BTC</strong>") {
+		t.Errorf("html part = %q", contents[1])
+	}
+}
+
+func TestSendEmailAlertReusesConnectionWithLoginAuth(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewEmailNotifier(server.config(config.SMTPAuthLogin), fakeSuppressionList{}, nil)
+
+	for i := 0; i < 2; i++ {
+		if err := notifier.SendEmailAlert("user@example.com", "Alert "+strconv.Itoa(i), pkg.EmailBody{HTML: "<p>body</p>"}); err != nil {
+			t.Fatalf("SendEmailAlert %d: %v", i, err)
+		}
+	}
+
+	connections, auths, messages := server.snapshot()
+	if connections != 1 {
+		t.Errorf("opened %d connections, want the first one reused", connections)
+	}
+	if len(auths) != 1 || auths[0] != "LOGIN alerts:secret" {
+		t.Errorf("auth = %v, want a single LOGIN", auths)
+	}
+	if len(messages) != 2 {
+		t.Fatalf("got %d messages, want 2", len(messages))
+	}
+
+	message, err := mail.ReadMessage(strings.NewReader(messages[0].data))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if message.Header.Get("List-Unsubscribe") != "" {
+		t.Error("List-Unsubscribe set without action links")
+	}
+	if subject := message.Header.Get("Subject"); subject != "Alert 0" {
+		t.Errorf("ASCII Subject = %q, want it unencoded", subject)
+	}
+}
+
+func TestSendEmailAlertSkipsSuppressedAddress(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewEmailNotifier(server.config(config.SMTPAuthNone), fakeSuppressionList{"user@example.com": true}, nil)
+
+	if err := notifier.SendEmailAlert("user@example.com", "Alert", pkg.EmailBody{HTML: "<p>body</p>"}); err != nil {
+		t.Fatalf("SendEmailAlert: %v", err)
+	}
+
+	if connections, _, messages := server.snapshot(); connections != 0 || len(messages) != 0 {
+		t.Errorf("suppressed address got %d messages over %d connections", len(messages), connections)
+	}
+}
+
+// The line length limit of RFC 2045 is checked on the raw message, which
+// multipart.Reader hides by decoding the parts.
+func TestSendEmailAlertWrapsLongLines(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	notifier := NewEmailNotifier(server.config(config.SMTPAuthNone), fakeSuppressionList{}, nil)
+
+	body := pkg.EmailBody{HTML: "<p>" + strings.Repeat("preço ", 100) + "</p>"}
+	if err := notifier.SendEmailAlert("user@example.com", "Alert", body); err != nil {
+		t.Fatalf("SendEmailAlert: %v", err)
+	}
+
+	_, _, messages := server.snapshot()
+	for _, line := range strings.Split(messages[0].data, "\r\n") {
+		if len(line) > 78 {
+			t.Fatalf("line of %d characters: %q", len(line), line)
+		}
+	}
+}
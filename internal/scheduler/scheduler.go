@@ -0,0 +1,53 @@
+package scheduler
+
+import (
+	"context"
+	"log"
+	"time"
+)
+
+// Job runs every Interval until the scheduler's context is cancelled. Jobs
+// must be idempotent: a run may repeat work a previous run already did.
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(now time.Time) error
+}
+
+type Scheduler struct {
+	jobs []Job
+}
+
+func New(jobs ...Job) *Scheduler {
+	return &Scheduler{jobs: jobs}
+}
+
+// Start runs each job in its own goroutine, once right away and then on every
+// tick.
+func (s *Scheduler) Start(ctx context.Context) {
+	for _, job := range s.jobs {
+		go s.loop(ctx, job)
+	}
+}
+
+func (s *Scheduler) loop(ctx context.Context, job Job) {
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	s.run(job, time.Now())
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			s.run(job, now)
+		}
+	}
+}
+
+func (s *Scheduler) run(job Job, now time.Time) {
+	if err := job.Run(now); err != nil {
+		log.Printf("Scheduled job %s failed: %v", job.Name, err)
+	}
+}
@@ -0,0 +1,151 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	dbRepo "crypto-alerts/internal/repository/db"
+	"encoding/json"
+	"log"
+	"time"
+)
+
+// alertBatch collects the alerts of one scan so each recipient gets a single
+// email, or one per symbol when groupBySymbol is set. Alerts of
+// subscriptions in different languages never share an email, and critical
+// alerts are kept in groups of their own since they skip quiet hours.
+type alertBatch struct {
+	groupBySymbol bool
+	stream        *pkg.AlertStream
+	keys          []string
+	groups        map[string]*alertGroup
+}
+
+type alertGroup struct {
+	email    string
+	locale   string
+	critical bool
+	alerts   []pkg.AlertMessage
+	history  []*entity.AlertHistory
+}
+
+func newAlertBatch(groupBySymbol bool, stream *pkg.AlertStream) *alertBatch {
+	return &alertBatch{
+		groupBySymbol: groupBySymbol,
+		stream:        stream,
+		groups:        make(map[string]*alertGroup),
+	}
+}
+
+func (b *alertBatch) add(thresholdID int64, email string, critical bool, alerts ...pkg.AlertMessage) {
+	for _, alert := range alerts {
+		key := email
+		if b.groupBySymbol {
+			key += "/" + alert.Symbol
+		}
+		locale := pkg.GetLocale(alert.Locale).Tag
+		key += "/" + locale
+		if critical {
+			key += "/critical"
+		}
+
+		group, exists := b.groups[key]
+		if !exists {
+			group = &alertGroup{email: email, locale: locale, critical: critical}
+			b.groups[key] = group
+			b.keys = append(b.keys, key)
+		}
+		group.alerts = append(group.alerts, alert)
+		group.history = append(group.history, newAlertHistory(thresholdID, email, alert))
+	}
+}
+
+// send puts every group in the outbox along with its alert history, except
+// non-critical groups of users currently in quiet hours, which are queued
+// for delivery when the window ends.
+func (b *alertBatch) send(
+	outboxRepo dbRepo.OutboxRepository,
+	historyRepo dbRepo.AlertHistoryRepository,
+	settingsRepo dbRepo.NotificationSettingsRepository,
+	now time.Time,
+) {
+	if len(b.keys) == 0 {
+		return
+	}
+
+	emails := make([]string, 0, len(b.groups))
+	for _, group := range b.groups {
+		emails = append(emails, group.email)
+	}
+
+	settings, err := settingsRepo.GetByEmails(emails)
+	if err != nil {
+		log.Printf("Failed to load notification settings, sending without quiet hours: %v", err)
+		settings = map[string]*entity.NotificationSettings{}
+	}
+
+	for _, key := range b.keys {
+		group := b.groups[key]
+
+		subject := pkg.FormatBatchEmailSubject(group.alerts)
+		body, err := pkg.FormatBatchEmailBody(group.alerts)
+		if err != nil {
+			log.Printf("Dropping email with %d alerts for %s: %v", len(group.alerts), group.email, err)
+			continue
+		}
+
+		if !group.critical && settings[group.email].InQuietHours(now) {
+			for _, entry := range group.history {
+				recordAlertHistory(historyRepo, b.stream, entry)
+			}
+
+			queued := &entity.QueuedAlert{Email: group.email, Subject: subject, Body: body.HTML, TextBody: body.Text, Locale: group.locale, CreatedAt: now}
+			if err := settingsRepo.Enqueue(queued); err != nil {
+				log.Printf("Failed to queue alerts for %s during quiet hours: %v", group.email, err)
+			} else {
+				log.Printf("%d alerts for %s queued until quiet hours end", len(group.alerts), group.email)
+			}
+			continue
+		}
+
+		message := &entity.OutboxMessage{Email: group.email, Subject: subject, Body: body.HTML, TextBody: body.Text, CreatedAt: now}
+		if err := outboxRepo.Enqueue(message, group.history); err != nil {
+			log.Printf("Failed to enqueue email with %d alerts for %s: %v", len(group.alerts), group.email, err)
+		} else {
+			log.Printf("Email with %d alerts for %s added to the outbox", len(group.alerts), group.email)
+			for _, entry := range group.history {
+				b.stream.Publish(entry)
+			}
+		}
+	}
+}
+
+// newAlertHistory keeps the alert itself for the stream, leaving out the
+// chart data and the recipient's signed links.
+func newAlertHistory(thresholdID int64, email string, alert pkg.AlertMessage) *entity.AlertHistory {
+	streamed := alert
+	streamed.HistoricalData = nil
+	streamed.Actions = nil
+	message, err := json.Marshal(streamed)
+	if err != nil {
+		log.Printf("Failed to encode %s alert for the stream: %v", alert.Symbol, err)
+	}
+
+	return &entity.AlertHistory{
+		ThresholdID: thresholdID,
+		Email:       email,
+		Symbol:      alert.Symbol,
+		Period:      alert.Period,
+		Direction:   alert.Direction,
+		Price:       alert.Price,
+		Subject:     pkg.FormatEmailSubject(alert),
+		Message:     message,
+	}
+}
+
+func recordAlertHistory(historyRepo dbRepo.AlertHistoryRepository, stream *pkg.AlertStream, entry *entity.AlertHistory) {
+	if err := historyRepo.Record(entry); err != nil {
+		log.Printf("Failed to record alert history for %s: %v", entry.Email, err)
+		return
+	}
+	stream.Publish(entry)
+}
@@ -0,0 +1,30 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/pkg"
+	"testing"
+)
+
+func TestAlertBatchGroupsByLocale(t *testing.T) {
+	batch := newAlertBatch(false, pkg.NewAlertStream())
+	batch.add(1, "user@example.com", false, pkg.AlertMessage{Symbol: "BTC", Locale: pkg.LocaleEnglish})
+	batch.add(2, "user@example.com", false, pkg.AlertMessage{Symbol: "ETH", Locale: "pt_br"})
+	batch.add(3, "user@example.com", false, pkg.AlertMessage{Symbol: "SOL"})
+	batch.add(4, "user@example.com", false, pkg.AlertMessage{Symbol: "ADA", Locale: "en-US"})
+
+	if len(batch.keys) != 2 {
+		t.Fatalf("got groups %v, want one per locale", batch.keys)
+	}
+	for _, key := range batch.keys {
+		group := batch.groups[key]
+		locale := pkg.GetLocale(group.alerts[0].Locale)
+		for _, alert := range group.alerts {
+			if pkg.GetLocale(alert.Locale) != locale {
+				t.Errorf("group %s mixes %s and %s alerts", key, locale.Tag, pkg.GetLocale(alert.Locale).Tag)
+			}
+		}
+		if len(group.alerts) != 2 {
+			t.Errorf("group %s has %d alerts, want 2", key, len(group.alerts))
+		}
+	}
+}
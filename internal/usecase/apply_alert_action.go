@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+	"log"
+	"time"
+)
+
+type ApplyAlertActionUseCase interface {
+	Verify(token string) (*entity.AlertAction, error)
+	Execute(token string) (*entity.AlertAction, error)
+}
+
+type applyAlertActionUseCase struct {
+	alertRepo    db.AlertThresholdRepository
+	actionSigner *pkg.ActionSigner
+}
+
+func NewApplyAlertActionUseCase(alertRepo db.AlertThresholdRepository, actionSigner *pkg.ActionSigner) ApplyAlertActionUseCase {
+	return &applyAlertActionUseCase{
+		alertRepo:    alertRepo,
+		actionSigner: actionSigner,
+	}
+}
+
+// Verify only accepts the per-alert actions; unsubscribe links have their
+// own endpoint.
+func (uc *applyAlertActionUseCase) Verify(token string) (*entity.AlertAction, error) {
+	return uc.verify(token, time.Now())
+}
+
+func (uc *applyAlertActionUseCase) verify(token string, now time.Time) (*entity.AlertAction, error) {
+	action, err := uc.actionSigner.Verify(token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action.Action {
+	case entity.AlertActionSnooze1h, entity.AlertActionSnooze24h, entity.AlertActionDisableRule, entity.AlertActionUnsubscribeSymbol:
+		return action, nil
+	}
+	return nil, pkg.ErrInvalidActionToken
+}
+
+// Execute applies the action of a signed link. The signature stands in for
+// a login, so the action is always scoped to the email it was issued for.
+func (uc *applyAlertActionUseCase) Execute(token string) (*entity.AlertAction, error) {
+	now := time.Now()
+
+	action, err := uc.verify(token, now)
+	if err != nil {
+		return nil, err
+	}
+
+	switch action.Action {
+	case entity.AlertActionSnooze1h, entity.AlertActionSnooze24h:
+		err = uc.alertRepo.Snooze(action.ThresholdID, action.Email, now.Add(entity.AlertActionSnoozes[action.Action]))
+	case entity.AlertActionDisableRule:
+		err = uc.alertRepo.DisableRule(action.ThresholdID, action.RuleID, action.Email)
+	case entity.AlertActionUnsubscribeSymbol:
+		var paused int
+		paused, err = uc.alertRepo.PauseSymbol(action.Email, action.Symbol)
+		if err == nil {
+			log.Printf("Paused %d %s alerts of %s from an email link", paused, action.Symbol, action.Email)
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	log.Printf("Applied %s to threshold %d of %s from an email link", action.Action, action.ThresholdID, action.Email)
+
+	return action, nil
+}
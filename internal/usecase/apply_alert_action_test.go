@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+	"errors"
+	"net/url"
+	"testing"
+	"time"
+)
+
+type snoozeRecorder struct {
+	db.AlertThresholdRepository
+
+	id    int64
+	email string
+	until time.Time
+}
+
+func (r *snoozeRecorder) Snooze(id int64, email string, until time.Time) error {
+	r.id, r.email, r.until = id, email, until
+	return nil
+}
+
+func TestApplyAlertActionSnoozeLink(t *testing.T) {
+	signer := pkg.NewActionSigner("https://alerts.example.com", "secret")
+	repo := &snoozeRecorder{}
+	uc := NewApplyAlertActionUseCase(repo, signer)
+
+	links := signer.Links(42, 0, "user@example.com", "BTC", pkg.LocaleEnglish, time.Now())
+	if len(links) == 0 || links[0].Action != entity.AlertActionSnooze1h {
+		t.Fatalf("expected a snooze link first, got %+v", links)
+	}
+	parsed, err := url.Parse(links[0].URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token := parsed.Query().Get("token")
+
+	action, err := uc.Verify(token)
+	if err != nil {
+		t.Fatalf("Verify: %v", err)
+	}
+	if action.Action != entity.AlertActionSnooze1h || action.ThresholdID != 42 || action.Email != "user@example.com" {
+		t.Fatalf("Verify returned %+v", action)
+	}
+	if repo.id != 0 {
+		t.Fatal("Verify must not apply the action")
+	}
+
+	before := time.Now()
+	if _, err := uc.Execute(token); err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if repo.id != 42 || repo.email != "user@example.com" {
+		t.Fatalf("snoozed threshold %d of %s", repo.id, repo.email)
+	}
+	if repo.until.Before(before.Add(time.Hour)) || repo.until.After(time.Now().Add(time.Hour)) {
+		t.Fatalf("snoozed until %v, expected one hour from now", repo.until)
+	}
+}
+
+func TestApplyAlertActionRejectsTamperedToken(t *testing.T) {
+	signer := pkg.NewActionSigner("https://alerts.example.com", "secret")
+	uc := NewApplyAlertActionUseCase(&snoozeRecorder{}, signer)
+
+	token := signer.Sign(entity.AlertAction{Action: entity.AlertActionSnooze1h, ThresholdID: 1, Email: "a@example.com", ExpiresAt: time.Now().Add(time.Hour).Unix()})
+	if _, err := uc.Execute(token + "x"); !errors.Is(err, pkg.ErrInvalidActionToken) {
+		t.Fatalf("expected ErrInvalidActionToken, got %v", err)
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+	"log"
+	"time"
+)
+
+type ConfirmEmailUseCase interface {
+	Verify(token string) (*entity.AlertAction, error)
+	Execute(token string) (*entity.AlertAction, int, error)
+}
+
+type confirmEmailUseCase struct {
+	alertRepo    db.AlertThresholdRepository
+	verifiedRepo db.VerifiedEmailRepository
+	actionSigner *pkg.ActionSigner
+}
+
+func NewConfirmEmailUseCase(
+	alertRepo db.AlertThresholdRepository,
+	verifiedRepo db.VerifiedEmailRepository,
+	actionSigner *pkg.ActionSigner,
+) ConfirmEmailUseCase {
+	return &confirmEmailUseCase{
+		alertRepo:    alertRepo,
+		verifiedRepo: verifiedRepo,
+		actionSigner: actionSigner,
+	}
+}
+
+func (uc *confirmEmailUseCase) Verify(token string) (*entity.AlertAction, error) {
+	action, err := uc.actionSigner.Verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if action.Action != entity.AlertActionConfirmEmail {
+		return nil, pkg.ErrInvalidActionToken
+	}
+	return action, nil
+}
+
+// Execute marks the address as verified and activates its pending
+// subscriptions, returning how many were activated.
+func (uc *confirmEmailUseCase) Execute(token string) (*entity.AlertAction, int, error) {
+	action, err := uc.Verify(token)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if err := uc.verifiedRepo.MarkVerified(action.Email); err != nil {
+		return nil, 0, err
+	}
+
+	activated, err := uc.alertRepo.ActivatePending(action.Email)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	log.Printf("%s confirmed, %d pending subscriptions activated", action.Email, activated)
+
+	return action, activated, nil
+}
@@ -2,8 +2,13 @@ package usecase
 
 import (
 	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/pkg/expression"
 	"crypto-alerts/internal/repository/db"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 )
 
 type CreateAlertUseCase interface {
@@ -11,21 +16,74 @@ type CreateAlertUseCase interface {
 }
 
 type createAlertUseCase struct {
-	alertRepo db.AlertThresholdRepository
+	alertRepo     db.AlertThresholdRepository
+	emailVerifier *EmailVerifier
 }
 
-func NewCreateAlertUseCase(alertRepo db.AlertThresholdRepository) CreateAlertUseCase {
+// NewCreateAlertUseCase keeps subscriptions of unconfirmed addresses
+// pending until a signed confirmation link is followed.
+func NewCreateAlertUseCase(alertRepo db.AlertThresholdRepository, emailVerifier *EmailVerifier) CreateAlertUseCase {
 	return &createAlertUseCase{
-		alertRepo: alertRepo,
+		alertRepo:     alertRepo,
+		emailVerifier: emailVerifier,
 	}
 }
 
 func (uc *createAlertUseCase) Execute(alertThreshold *entity.AlertThreshold) error {
+	if alertThreshold.State == "" {
+		alertThreshold.State = entity.AlertStateActive
+	}
+	alertThreshold.TriggerCount = 0
+	alertThreshold.DigestFrequency = strings.ToLower(strings.TrimSpace(alertThreshold.DigestFrequency))
+	alertThreshold.QuoteSymbol = strings.ToUpper(strings.TrimSpace(alertThreshold.QuoteSymbol))
+	if strings.TrimSpace(alertThreshold.Locale) == "" {
+		alertThreshold.Locale = pkg.DefaultLocale
+	} else if locale := pkg.NormalizeLocale(alertThreshold.Locale); locale != "" {
+		alertThreshold.Locale = locale
+	}
+	alertThreshold.DepegConsecutiveScans = 0
+	if alertThreshold.RangeLookbackDays == 0 {
+		alertThreshold.RangeLookbackDays = entity.DefaultRangeLookbackDays
+	}
+	alertThreshold.VolatilityRegimeActive = false
+	if alertThreshold.VolatilityShortWindow == 0 {
+		alertThreshold.VolatilityShortWindow = entity.DefaultVolatilityShortWindow
+	}
+	if alertThreshold.VolatilityLongWindow == 0 {
+		alertThreshold.VolatilityLongWindow = entity.DefaultVolatilityLongWindow
+	}
+	if alertThreshold.DepegEnabled {
+		if alertThreshold.DepegPegPrice == nil {
+			pegPrice := entity.DefaultPegPrice
+			alertThreshold.DepegPegPrice = &pegPrice
+		}
+		if alertThreshold.DepegSustainedScans == 0 {
+			alertThreshold.DepegSustainedScans = 1
+		}
+	}
+
 	if err := uc.validate(alertThreshold); err != nil {
 		return err
 	}
 
-	return uc.alertRepo.Create(alertThreshold)
+	verified, err := uc.emailVerifier.isVerified(alertThreshold.Email)
+	if err != nil {
+		return err
+	}
+	if !verified {
+		alertThreshold.RequestedState = alertThreshold.State
+		alertThreshold.State = entity.AlertStatePending
+	}
+
+	if err := uc.alertRepo.Create(alertThreshold); err != nil {
+		return err
+	}
+
+	if !verified {
+		uc.emailVerifier.requestConfirmation(alertThreshold.Email, alertThreshold.CryptoSymbol, alertThreshold.Locale)
+	}
+
+	return nil
 }
 
 func (uc *createAlertUseCase) validate(alertThreshold *entity.AlertThreshold) error {
@@ -37,78 +95,147 @@ func (uc *createAlertUseCase) validate(alertThreshold *entity.AlertThreshold) er
 		return fmt.Errorf("crypto symbol is required")
 	}
 
-	if alertThreshold.ThresholdUp1hEnabled && alertThreshold.ThresholdUp1hPercent == nil {
-		return fmt.Errorf("threshold up 1h percent is required when enabled")
+	if strings.EqualFold(alertThreshold.QuoteSymbol, alertThreshold.CryptoSymbol) {
+		return fmt.Errorf("quote symbol must differ from crypto symbol")
 	}
-	if alertThreshold.ThresholdDown1hEnabled && alertThreshold.ThresholdDown1hPercent == nil {
-		return fmt.Errorf("threshold down 1h percent is required when enabled")
+
+	if !entity.DigestFrequencies[alertThreshold.DigestFrequency] {
+		return fmt.Errorf("digest frequency must be empty, %s or %s", entity.DigestFrequencyDaily, entity.DigestFrequencyWeekly)
 	}
-	if alertThreshold.ThresholdDown1hEnabled && alertThreshold.ThresholdDown1hPercent != nil && *alertThreshold.ThresholdDown1hPercent >= 0 {
-		return fmt.Errorf("threshold down 1h percent must be negative")
+
+	if pkg.NormalizeLocale(alertThreshold.Locale) == "" {
+		return fmt.Errorf("locale must be %s, %s or %s", pkg.LocalePortuguese, pkg.LocaleEnglish, pkg.LocaleSpanish)
 	}
 
-	if alertThreshold.ThresholdUp24hEnabled && alertThreshold.ThresholdUp24hPercent == nil {
-		return fmt.Errorf("threshold up 24h percent is required when enabled")
+	seenRules := make(map[string]bool, len(alertThreshold.Rules))
+	for i := range alertThreshold.Rules {
+		rule := &alertThreshold.Rules[i]
+		if err := uc.validateRule(rule); err != nil {
+			return err
+		}
+
+		key := rule.Kind + "/" + rule.Period + "/" + rule.Direction
+		if seenRules[key] {
+			return fmt.Errorf("%s rule %s %s is defined more than once", rule.Kind, rule.Direction, rule.Period)
+		}
+		seenRules[key] = true
 	}
-	if alertThreshold.ThresholdDown24hEnabled && alertThreshold.ThresholdDown24hPercent == nil {
-		return fmt.Errorf("threshold down 24h percent is required when enabled")
+
+	if alertThreshold.CompositeRuleEnabled && alertThreshold.CompositeRule == nil {
+		return fmt.Errorf("composite rule is required when enabled")
 	}
-	if alertThreshold.ThresholdDown24hEnabled && alertThreshold.ThresholdDown24hPercent != nil && *alertThreshold.ThresholdDown24hPercent >= 0 {
-		return fmt.Errorf("threshold down 24h percent must be negative")
+	if alertThreshold.CompositeRule != nil {
+		if err := alertThreshold.CompositeRule.Validate(); err != nil {
+			return err
+		}
 	}
 
-	if alertThreshold.ThresholdUp7dEnabled && alertThreshold.ThresholdUp7dPercent == nil {
-		return fmt.Errorf("threshold up 7d percent is required when enabled")
+	if alertThreshold.CustomExpressionEnabled && alertThreshold.CustomExpression == nil {
+		return fmt.Errorf("custom expression is required when enabled")
 	}
-	if alertThreshold.ThresholdDown7dEnabled && alertThreshold.ThresholdDown7dPercent == nil {
-		return fmt.Errorf("threshold down 7d percent is required when enabled")
-	}
-	if alertThreshold.ThresholdDown7dEnabled && alertThreshold.ThresholdDown7dPercent != nil && *alertThreshold.ThresholdDown7dPercent >= 0 {
-		return fmt.Errorf("threshold down 7d percent must be negative")
+	if alertThreshold.CustomExpression != nil {
+		if _, err := expression.Compile(*alertThreshold.CustomExpression, entity.ConditionMetrics); err != nil {
+			return fmt.Errorf("invalid custom expression: %w", err)
+		}
 	}
 
-	if alertThreshold.ThresholdUp30dEnabled && alertThreshold.ThresholdUp30dPercent == nil {
-		return fmt.Errorf("threshold up 30d percent is required when enabled")
+	if alertThreshold.DepegEnabled && alertThreshold.DepegBandBps == nil {
+		return fmt.Errorf("depeg band bps is required when enabled")
+	}
+	if alertThreshold.DepegEnabled && *alertThreshold.DepegBandBps <= 0 {
+		return fmt.Errorf("depeg band bps must be positive")
 	}
-	if alertThreshold.ThresholdDown30dEnabled && alertThreshold.ThresholdDown30dPercent == nil {
-		return fmt.Errorf("threshold down 30d percent is required when enabled")
+	if alertThreshold.DepegEnabled && *alertThreshold.DepegPegPrice <= 0 {
+		return fmt.Errorf("depeg peg price must be positive")
 	}
-	if alertThreshold.ThresholdDown30dEnabled && alertThreshold.ThresholdDown30dPercent != nil && *alertThreshold.ThresholdDown30dPercent >= 0 {
-		return fmt.Errorf("threshold down 30d percent must be negative")
+	if alertThreshold.DepegEnabled && alertThreshold.DepegSustainedScans < 1 {
+		return fmt.Errorf("depeg sustained scans must be at least 1")
+	}
+	if alertThreshold.DepegEnabled && alertThreshold.QuoteSymbol != "" {
+		return fmt.Errorf("depeg alerts are only supported against USD")
 	}
 
-	if alertThreshold.ThresholdUp60dEnabled && alertThreshold.ThresholdUp60dPercent == nil {
-		return fmt.Errorf("threshold up 60d percent is required when enabled")
+	if !entity.RangeLookbackDays[alertThreshold.RangeLookbackDays] {
+		return fmt.Errorf("range lookback days must be 30, 90 or 365")
 	}
-	if alertThreshold.ThresholdDown60dEnabled && alertThreshold.ThresholdDown60dPercent == nil {
-		return fmt.Errorf("threshold down 60d percent is required when enabled")
+	if alertThreshold.DrawdownEnabled && alertThreshold.DrawdownPercent == nil {
+		return fmt.Errorf("drawdown percent is required when enabled")
 	}
-	if alertThreshold.ThresholdDown60dEnabled && alertThreshold.ThresholdDown60dPercent != nil && *alertThreshold.ThresholdDown60dPercent >= 0 {
-		return fmt.Errorf("threshold down 60d percent must be negative")
+	if alertThreshold.DrawdownEnabled && (*alertThreshold.DrawdownPercent <= 0 || *alertThreshold.DrawdownPercent >= 100) {
+		return fmt.Errorf("drawdown percent must be between 0 and 100")
 	}
 
-	if alertThreshold.ThresholdUp90dEnabled && alertThreshold.ThresholdUp90dPercent == nil {
-		return fmt.Errorf("threshold up 90d percent is required when enabled")
+	if alertThreshold.VolatilityEnabled && alertThreshold.VolatilityMultiple == nil {
+		return fmt.Errorf("volatility multiple is required when enabled")
 	}
-	if alertThreshold.ThresholdDown90dEnabled && alertThreshold.ThresholdDown90dPercent == nil {
-		return fmt.Errorf("threshold down 90d percent is required when enabled")
+	if alertThreshold.VolatilityEnabled && *alertThreshold.VolatilityMultiple <= 0 {
+		return fmt.Errorf("volatility multiple must be positive")
 	}
-	if alertThreshold.ThresholdDown90dEnabled && alertThreshold.ThresholdDown90dPercent != nil && *alertThreshold.ThresholdDown90dPercent >= 0 {
-		return fmt.Errorf("threshold down 90d percent must be negative")
+	if alertThreshold.VolatilityShortWindow < 2 {
+		return fmt.Errorf("volatility short window must be at least 2 days")
 	}
-
-	if alertThreshold.TargetPriceUpEnabled && alertThreshold.TargetPriceUp == nil {
-		return fmt.Errorf("target price up is required when enabled")
+	if alertThreshold.VolatilityLongWindow <= alertThreshold.VolatilityShortWindow {
+		return fmt.Errorf("volatility long window must be longer than the short window")
 	}
-	if alertThreshold.TargetPriceUpEnabled && alertThreshold.TargetPriceUp != nil && *alertThreshold.TargetPriceUp <= 0 {
-		return fmt.Errorf("target price up must be positive")
+	if alertThreshold.VolatilityLongWindow > entity.MaxVolatilityLongWindow {
+		return fmt.Errorf("volatility long window must be at most %d days", entity.MaxVolatilityLongWindow)
 	}
 
-	if alertThreshold.TargetPriceDownEnabled && alertThreshold.TargetPriceDown == nil {
-		return fmt.Errorf("target price down is required when enabled")
+	if alertThreshold.State != entity.AlertStateActive && alertThreshold.State != entity.AlertStatePaused {
+		return fmt.Errorf("state must be %s or %s", entity.AlertStateActive, entity.AlertStatePaused)
+	}
+	if alertThreshold.MaxTriggers != nil && *alertThreshold.MaxTriggers <= 0 {
+		return fmt.Errorf("max triggers must be positive")
 	}
-	if alertThreshold.TargetPriceDownEnabled && alertThreshold.TargetPriceDown != nil && *alertThreshold.TargetPriceDown <= 0 {
-		return fmt.Errorf("target price down must be positive")
+	if alertThreshold.ExpiresAt != nil && !alertThreshold.ExpiresAt.After(time.Now()) {
+		return fmt.Errorf("expires at must be in the future")
+	}
+	if alertThreshold.StartsAt != nil && alertThreshold.ExpiresAt != nil && !alertThreshold.StartsAt.Before(*alertThreshold.ExpiresAt) {
+		return fmt.Errorf("starts at must be before expires at")
+	}
+
+	return nil
+}
+
+func (uc *createAlertUseCase) validateRule(rule *entity.AlertRule) error {
+	if rule.Direction != entity.DirectionUp && rule.Direction != entity.DirectionDown {
+		return fmt.Errorf("%s rule direction must be %s or %s", rule.Kind, entity.DirectionUp, entity.DirectionDown)
+	}
+
+	switch rule.Kind {
+	case entity.RuleKindWindow:
+		rule.Period = strings.ToLower(strings.TrimSpace(rule.Period))
+
+		duration, err := entity.ParseWindow(rule.Period)
+		if err != nil {
+			return err
+		}
+		if rule.Source() == entity.WindowSourceHourlyHistory && duration > entity.MaxHourlyWindowDuration {
+			return fmt.Errorf("window %q must be given in whole days when longer than 89 days", rule.Period)
+		}
+		if rule.Direction == entity.DirectionUp && rule.Value <= 0 {
+			return fmt.Errorf("threshold up %s percent must be positive", rule.Period)
+		}
+		if rule.Direction == entity.DirectionDown && rule.Value >= 0 {
+			return fmt.Errorf("threshold down %s percent must be negative", rule.Period)
+		}
+
+	case entity.RuleKindTargetPrice:
+		rule.Period = ""
+		if rule.Value <= 0 {
+			return fmt.Errorf("target price %s must be positive", rule.Direction)
+		}
+		rounded := pkg.RoundPrice(rule.Value)
+		if rounded == 0 {
+			return fmt.Errorf("target price %s must have at most %d decimals", rule.Direction, pkg.MaxPriceDecimals)
+		}
+		if rounded != rule.Value {
+			return fmt.Errorf("target price %s %s is more precise than prices of this size are shown, use %s",
+				rule.Direction, strconv.FormatFloat(rule.Value, 'f', -1, 64), strconv.FormatFloat(rounded, 'f', -1, 64))
+		}
+
+	default:
+		return fmt.Errorf("rule kind %q is not supported", rule.Kind)
 	}
 
 	return nil
@@ -0,0 +1,102 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+	"crypto-alerts/internal/repository/notifier"
+	"log"
+	"time"
+)
+
+const (
+	// outboxBatchSize caps how many messages one run tries to deliver.
+	outboxBatchSize = 100
+	// maxOutboxBackoff caps the exponential delay between attempts.
+	maxOutboxBackoff = 6 * time.Hour
+	// outboxClaimTTL is how long claimed messages stay reserved for one run,
+	// after which a run that died mid-send no longer holds them.
+	outboxClaimTTL = 10 * time.Minute
+)
+
+type DeliverOutboxUseCase interface {
+	Execute(now time.Time) (int, error)
+}
+
+type deliverOutboxUseCase struct {
+	outboxRepo  db.OutboxRepository
+	notifier    notifier.Notifier
+	maxAttempts int
+	baseBackoff time.Duration
+}
+
+func NewDeliverOutboxUseCase(
+	outboxRepo db.OutboxRepository,
+	notifier notifier.Notifier,
+	maxAttempts int,
+	baseBackoff time.Duration,
+) DeliverOutboxUseCase {
+	return &deliverOutboxUseCase{
+		outboxRepo:  outboxRepo,
+		notifier:    notifier,
+		maxAttempts: maxAttempts,
+		baseBackoff: baseBackoff,
+	}
+}
+
+// Execute claims and sends the due messages, so runs overlapping here or on
+// other instances never send the same one. A failed message is retried
+// after baseBackoff, doubling on every attempt, and dead-lettered after
+// maxAttempts.
+func (uc *deliverOutboxUseCase) Execute(now time.Time) (int, error) {
+	lockedUntil := now.Add(outboxClaimTTL)
+	messages, err := uc.outboxRepo.Claim(now, lockedUntil, outboxBatchSize)
+	if err != nil {
+		log.Printf("Error getting due emails from the outbox: %v", err)
+		return 0, err
+	}
+
+	sent := 0
+	for i, message := range messages {
+		// Past the claim another run may pick up the rest, so leave them
+		if time.Now().After(lockedUntil) {
+			log.Printf("Outbox claim expired, leaving %d emails for the next run", len(messages)-i)
+			break
+		}
+
+		attempts := message.Attempts + 1
+
+		if err := uc.notifier.SendEmailAlert(message.Email, message.Subject, pkg.EmailBody{HTML: message.Body, Text: message.TextBody}); err != nil {
+			if attempts >= uc.maxAttempts {
+				log.Printf("Giving up on email %d to %s after %d attempts: %v", message.ID, message.Email, attempts, err)
+				if err := uc.outboxRepo.MarkDead(message.ID, attempts, err.Error()); err != nil {
+					log.Printf("Failed to dead-letter email %d: %v", message.ID, err)
+				}
+				continue
+			}
+
+			nextAttemptAt := now.Add(uc.backoff(attempts))
+			log.Printf("Failed to send email %d to %s (attempt %d), retrying at %s: %v",
+				message.ID, message.Email, attempts, nextAttemptAt.Format(time.RFC3339), err)
+			if err := uc.outboxRepo.MarkFailed(message.ID, attempts, nextAttemptAt, err.Error()); err != nil {
+				log.Printf("Failed to record failed attempt of email %d: %v", message.ID, err)
+			}
+			continue
+		}
+
+		if err := uc.outboxRepo.MarkSent(message.ID, time.Now()); err != nil {
+			log.Printf("Failed to mark email %d as sent: %v", message.ID, err)
+		}
+		log.Printf("Email %d sent to %s", message.ID, message.Email)
+		sent++
+	}
+
+	return sent, nil
+}
+
+func (uc *deliverOutboxUseCase) backoff(attempts int) time.Duration {
+	delay := uc.baseBackoff
+	for i := 1; i < attempts && delay < maxOutboxBackoff; i++ {
+		delay *= 2
+	}
+	return min(delay, maxOutboxBackoff)
+}
@@ -0,0 +1,141 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	dbRepo "crypto-alerts/internal/repository/db"
+	"log"
+	"time"
+)
+
+type DeliverQueuedAlertsUseCase interface {
+	Execute(now time.Time) (int, error)
+}
+
+type deliverQueuedAlertsUseCase struct {
+	settingsRepo dbRepo.NotificationSettingsRepository
+	outboxRepo   dbRepo.OutboxRepository
+}
+
+func NewDeliverQueuedAlertsUseCase(
+	settingsRepo dbRepo.NotificationSettingsRepository,
+	outboxRepo dbRepo.OutboxRepository,
+) DeliverQueuedAlertsUseCase {
+	return &deliverQueuedAlertsUseCase{
+		settingsRepo: settingsRepo,
+		outboxRepo:   outboxRepo,
+	}
+}
+
+// Execute moves the alerts queued for users whose quiet hours are over to
+// the outbox, either as they were queued or as one summary email per
+// language. Each move removes the alerts from the queue in the same
+// transaction. Their history was recorded when they were queued.
+func (uc *deliverQueuedAlertsUseCase) Execute(now time.Time) (int, error) {
+	queued, err := uc.settingsRepo.GetQueued()
+	if err != nil {
+		log.Printf("Error getting queued alerts from database: %v", err)
+		return 0, err
+	}
+
+	if len(queued) == 0 {
+		return 0, nil
+	}
+
+	byEmail := make(map[string][]*entity.QueuedAlert)
+	var emails []string
+	for _, alert := range queued {
+		if _, exists := byEmail[alert.Email]; !exists {
+			emails = append(emails, alert.Email)
+		}
+		byEmail[alert.Email] = append(byEmail[alert.Email], alert)
+	}
+
+	settings, err := uc.settingsRepo.GetByEmails(emails)
+	if err != nil {
+		return 0, err
+	}
+
+	delivered := 0
+	for _, email := range emails {
+		userSettings := settings[email]
+		if userSettings.InQuietHours(now) {
+			continue
+		}
+
+		var sent []int64
+		summarize := userSettings != nil && userSettings.SummarizeQueued && len(byEmail[email]) > 1
+		if summarize {
+			summaries, ids, err := queuedSummaries(email, byEmail[email])
+			if err != nil {
+				log.Printf("Failed to render quiet hours summary for %s, releasing the alerts one by one: %v", email, err)
+				summarize = false
+			} else if err := uc.outboxRepo.ReleaseQueued(summaries, ids); err != nil {
+				log.Printf("Failed to enqueue quiet hours summary for %s: %v", email, err)
+				continue
+			} else {
+				sent = ids
+			}
+		}
+		if !summarize {
+			for _, alert := range byEmail[email] {
+				message := &entity.OutboxMessage{Email: email, Subject: alert.Subject, Body: alert.Body, TextBody: alert.TextBody}
+				if err := uc.outboxRepo.ReleaseQueued([]*entity.OutboxMessage{message}, []int64{alert.ID}); err != nil {
+					log.Printf("Failed to enqueue queued alert %d for %s: %v", alert.ID, email, err)
+					continue
+				}
+				sent = append(sent, alert.ID)
+			}
+		}
+
+		if len(sent) == 0 {
+			continue
+		}
+
+		log.Printf("Released %d queued alerts for %s after quiet hours", len(sent), email)
+		delivered += len(sent)
+	}
+
+	return delivered, nil
+}
+
+// queuedSummaries renders one summary per language of the queued alerts and
+// returns them with the IDs of the alerts they cover.
+func queuedSummaries(email string, queued []*entity.QueuedAlert) ([]*entity.OutboxMessage, []int64, error) {
+	var summaries []*entity.OutboxMessage
+	var ids []int64
+	for _, alerts := range groupQueuedByLocale(queued) {
+		body, err := pkg.FormatQueuedSummaryEmailBody(alerts)
+		if err != nil {
+			return nil, nil, err
+		}
+		summaries = append(summaries, &entity.OutboxMessage{
+			Email:    email,
+			Subject:  pkg.FormatQueuedSummaryEmailSubject(alerts),
+			Body:     body.HTML,
+			TextBody: body.Text,
+		})
+		for _, alert := range alerts {
+			ids = append(ids, alert.ID)
+		}
+	}
+	return summaries, ids, nil
+}
+
+// groupQueuedByLocale splits the alerts of one user by language, keeping
+// their order, so each summary is written in a single locale.
+func groupQueuedByLocale(alerts []*entity.QueuedAlert) [][]*entity.QueuedAlert {
+	index := make(map[string]int)
+	var groups [][]*entity.QueuedAlert
+	for _, alert := range alerts {
+		tag := pkg.GetLocale(alert.Locale).Tag
+		i, exists := index[tag]
+		if !exists {
+			i = len(groups)
+			index[tag] = i
+			groups = append(groups, nil)
+		}
+		groups[i] = append(groups[i], alert)
+	}
+	return groups
+}
@@ -0,0 +1,73 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+type queuedSettingsRepo struct {
+	db.NotificationSettingsRepository
+	settings map[string]*entity.NotificationSettings
+	queued   []*entity.QueuedAlert
+}
+
+func (r *queuedSettingsRepo) GetByEmails(emails []string) (map[string]*entity.NotificationSettings, error) {
+	return r.settings, nil
+}
+
+func (r *queuedSettingsRepo) GetQueued() ([]*entity.QueuedAlert, error) {
+	return r.queued, nil
+}
+
+type releaseRecorder struct {
+	db.OutboxRepository
+	subjects []string
+	released [][]int64
+}
+
+func (r *releaseRecorder) ReleaseQueued(messages []*entity.OutboxMessage, queuedIDs []int64) error {
+	for _, message := range messages {
+		r.subjects = append(r.subjects, message.Subject)
+	}
+	r.released = append(r.released, queuedIDs)
+	return nil
+}
+
+func TestDeliverQueuedAlertsReleasesSummariesPerLocale(t *testing.T) {
+	createdAt := time.Date(2026, 3, 14, 2, 30, 0, 0, time.UTC)
+	settingsRepo := &queuedSettingsRepo{
+		settings: map[string]*entity.NotificationSettings{
+			"summary@example.com": {Email: "summary@example.com", SummarizeQueued: true},
+		},
+		queued: []*entity.QueuedAlert{
+			{ID: 1, Email: "summary@example.com", Subject: "BTC", Locale: pkg.LocaleEnglish, CreatedAt: createdAt},
+			{ID: 2, Email: "summary@example.com", Subject: "ETH", Locale: pkg.LocalePortuguese, CreatedAt: createdAt},
+			{ID: 3, Email: "summary@example.com", Subject: "SOL", Locale: pkg.LocaleEnglish, CreatedAt: createdAt},
+			{ID: 4, Email: "plain@example.com", Subject: "ADA", CreatedAt: createdAt},
+			{ID: 5, Email: "plain@example.com", Subject: "DOT", CreatedAt: createdAt},
+		},
+	}
+	outbox := &releaseRecorder{}
+
+	delivered, err := NewDeliverQueuedAlertsUseCase(settingsRepo, outbox).Execute(createdAt.Add(8 * time.Hour))
+	if err != nil {
+		t.Fatalf("Execute: %v", err)
+	}
+	if delivered != 5 {
+		t.Errorf("delivered %d alerts, want 5", delivered)
+	}
+
+	if want := [][]int64{{1, 3, 2}, {4}, {5}}; !reflect.DeepEqual(outbox.released, want) {
+		t.Errorf("released %v, want the summary in one transaction and the rest one by one", outbox.released)
+	}
+	if len(outbox.subjects) != 4 ||
+		!strings.Contains(outbox.subjects[0], "quiet hours") ||
+		!strings.Contains(outbox.subjects[1], "horário de silêncio") {
+		t.Errorf("subjects %q, want one English and one Portuguese summary first", outbox.subjects)
+	}
+}
@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+	"crypto-alerts/internal/repository/notifier"
+	"log"
+	"time"
+)
+
+// EmailVerifier is the double opt-in gate shared by every use case that
+// mails an address. When opt-in is not required every address counts as
+// verified.
+type EmailVerifier struct {
+	verifiedRepo  db.VerifiedEmailRepository
+	notifier      notifier.Notifier
+	actionSigner  *pkg.ActionSigner
+	pendingExpiry time.Duration
+	required      bool
+}
+
+// NewEmailVerifier expects a signer whenever opt-in is required, which the
+// configuration validation guarantees.
+func NewEmailVerifier(
+	verifiedRepo db.VerifiedEmailRepository,
+	notifier notifier.Notifier,
+	actionSigner *pkg.ActionSigner,
+	pendingExpiry time.Duration,
+	required bool,
+) *EmailVerifier {
+	return &EmailVerifier{
+		verifiedRepo:  verifiedRepo,
+		notifier:      notifier,
+		actionSigner:  actionSigner,
+		pendingExpiry: pendingExpiry,
+		required:      required,
+	}
+}
+
+func (v *EmailVerifier) isVerified(email string) (bool, error) {
+	if !v.required {
+		return true, nil
+	}
+	return v.verifiedRepo.IsVerified(email)
+}
+
+// requestConfirmation mails the signed link that verifies the address, in
+// the language of the subscription that asked for it. Failures are only
+// logged since the subscription is kept either way.
+func (v *EmailVerifier) requestConfirmation(email string, symbol string, locale string) {
+	confirmationURL := v.actionSigner.ConfirmationURL(email, locale, v.pendingExpiry, time.Now())
+	subject := pkg.FormatConfirmationEmailSubject(symbol, locale)
+	body, err := pkg.FormatConfirmationEmailBody(symbol, confirmationURL, int(v.pendingExpiry.Hours()), locale)
+	if err != nil {
+		log.Printf("Failed to render confirmation email to %s: %v", email, err)
+		return
+	}
+
+	if err := v.notifier.SendEmailAlert(email, subject, body); err != nil {
+		log.Printf("Failed to send confirmation email to %s: %v", email, err)
+		return
+	}
+
+	log.Printf("Confirmation email sent to %s for %s", email, symbol)
+}
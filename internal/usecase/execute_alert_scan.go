@@ -3,10 +3,25 @@ package usecase
 import (
 	"crypto-alerts/internal/entity"
 	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/pkg/expression"
 	apiRepo "crypto-alerts/internal/repository/api"
 	dbRepo "crypto-alerts/internal/repository/db"
-	notifierRepo "crypto-alerts/internal/repository/notifier"
+	"errors"
+	"fmt"
 	"log"
+	"math"
+	"time"
+)
+
+// defaultHistoryDays is the history shown in emails and bound to the *_90d
+// variables. Range and volatility alerts may fetch a longer history.
+const defaultHistoryDays = 90
+
+// Daily points are stamped at 00:00 UTC and hourly ones on the hour, so the
+// reference price for a window may precede its start by up to one step.
+const (
+	dailyWindowTolerance  = 24 * time.Hour
+	hourlyWindowTolerance = time.Hour
 )
 
 type ExecuteAlertScanUseCase interface {
@@ -15,22 +30,37 @@ type ExecuteAlertScanUseCase interface {
 
 type executeAlertScanUseCase struct {
 	alertRepo         dbRepo.AlertThresholdRepository
+	historyRepo       dbRepo.AlertHistoryRepository
+	settingsRepo      dbRepo.NotificationSettingsRepository
 	coinMarketCapRepo apiRepo.CoinMarketCapRepository
 	coinGeckoRepo     apiRepo.CoinGeckoRepository
-	notifier          notifierRepo.Notifier
+	outboxRepo        dbRepo.OutboxRepository
+	actionSigner      *pkg.ActionSigner
+	stream            *pkg.AlertStream
+	groupBySymbol     bool
 }
 
 func NewExecuteAlertScanUseCase(
 	alertRepo dbRepo.AlertThresholdRepository,
+	historyRepo dbRepo.AlertHistoryRepository,
+	settingsRepo dbRepo.NotificationSettingsRepository,
 	coinMarketCapRepo apiRepo.CoinMarketCapRepository,
 	coinGeckoRepo apiRepo.CoinGeckoRepository,
-	notifier notifierRepo.Notifier,
+	outboxRepo dbRepo.OutboxRepository,
+	actionSigner *pkg.ActionSigner,
+	stream *pkg.AlertStream,
+	groupBySymbol bool,
 ) ExecuteAlertScanUseCase {
 	return &executeAlertScanUseCase{
 		alertRepo:         alertRepo,
+		historyRepo:       historyRepo,
+		settingsRepo:      settingsRepo,
 		coinMarketCapRepo: coinMarketCapRepo,
 		coinGeckoRepo:     coinGeckoRepo,
-		notifier:          notifier,
+		outboxRepo:        outboxRepo,
+		actionSigner:      actionSigner,
+		stream:            stream,
+		groupBySymbol:     groupBySymbol,
 	}
 }
 
@@ -46,13 +76,22 @@ func (uc *executeAlertScanUseCase) Execute() ([]pkg.AlertMessage, error) {
 		return []pkg.AlertMessage{}, nil
 	}
 
-	symbolsMap := make(map[string]bool)
+	historyDays := make(map[string]int)
+	hourlyDays := make(map[string]int)
 	for _, threshold := range thresholds {
-		symbolsMap[threshold.CryptoSymbol] = true
+		days := max(defaultHistoryDays, threshold.RequiredHistoryDays())
+		hours := threshold.RequiredHourlyHistoryDays()
+
+		historyDays[threshold.CryptoSymbol] = max(historyDays[threshold.CryptoSymbol], days)
+		hourlyDays[threshold.CryptoSymbol] = max(hourlyDays[threshold.CryptoSymbol], hours)
+		if threshold.QuoteSymbol != "" {
+			historyDays[threshold.QuoteSymbol] = max(historyDays[threshold.QuoteSymbol], days)
+			hourlyDays[threshold.QuoteSymbol] = max(hourlyDays[threshold.QuoteSymbol], hours)
+		}
 	}
 
-	symbols := make([]string, 0, len(symbolsMap))
-	for symbol := range symbolsMap {
+	symbols := make([]string, 0, len(historyDays))
+	for symbol := range historyDays {
 		symbols = append(symbols, symbol)
 	}
 
@@ -70,7 +109,7 @@ func (uc *executeAlertScanUseCase) Execute() ([]pkg.AlertMessage, error) {
 
 	historicalDataMap := make(map[string]*entity.HistoricalPriceData)
 	for _, symbol := range symbols {
-		historicalData, err := uc.coinGeckoRepo.GetHistoricalPrices(symbol, 90)
+		historicalData, err := uc.coinGeckoRepo.GetHistoricalPrices(symbol, historyDays[symbol])
 		if err != nil {
 			log.Printf("Warning: Failed to get historical data for %s: %v", symbol, err)
 			continue
@@ -78,7 +117,20 @@ func (uc *executeAlertScanUseCase) Execute() ([]pkg.AlertMessage, error) {
 		historicalDataMap[symbol] = historicalData
 	}
 
-	alerts := uc.processAlerts(thresholds, cryptoData, fearGreed, historicalDataMap)
+	hourlyDataMap := make(map[string]*entity.HistoricalPriceData)
+	for symbol, days := range hourlyDays {
+		if days == 0 {
+			continue
+		}
+		hourlyData, err := uc.coinGeckoRepo.GetHourlyPrices(symbol, days)
+		if err != nil {
+			log.Printf("Warning: Failed to get hourly data for %s: %v", symbol, err)
+			continue
+		}
+		hourlyDataMap[symbol] = hourlyData
+	}
+
+	alerts := uc.processAlerts(thresholds, cryptoData, fearGreed, historicalDataMap, hourlyDataMap)
 
 	log.Printf("Processed %d thresholds and generated %d alerts", len(thresholds), len(alerts))
 
@@ -90,89 +142,179 @@ func (uc *executeAlertScanUseCase) processAlerts(
 	cryptoData map[string]*entity.CryptoCurrency,
 	fearGreed *entity.FearGreedIndex,
 	historicalDataMap map[string]*entity.HistoricalPriceData,
+	hourlyDataMap map[string]*entity.HistoricalPriceData,
 ) []pkg.AlertMessage {
 	var alerts []pkg.AlertMessage
+	batch := newAlertBatch(uc.groupBySymbol, uc.stream)
+	now := time.Now()
 
 	for _, threshold := range thresholds {
-		data, exists := cryptoData[threshold.CryptoSymbol]
-		if !exists {
+		if !uc.isEvaluable(threshold, now) {
 			continue
 		}
 
-		historicalData := historicalDataMap[threshold.CryptoSymbol]
-
-		alertsFound := false
-
-		if threshold.ThresholdUp1hEnabled && threshold.ThresholdUp1hPercent != nil {
-			alertsFound = uc.checkUserVarThresholdUp(threshold, data, "1h", data.PercentChange1h,
-				*threshold.ThresholdUp1hPercent, fearGreed, historicalData, &alerts) || alertsFound
-		}
-		if threshold.ThresholdDown1hEnabled && threshold.ThresholdDown1hPercent != nil {
-			alertsFound = uc.checkUserVarThresholdDown(threshold, data, "1h", data.PercentChange1h,
-				*threshold.ThresholdDown1hPercent, fearGreed, historicalData, &alerts) || alertsFound
+		data, fullHistory, hourlyHistory, exists := resolveMarketData(threshold, cryptoData, historicalDataMap, hourlyDataMap)
+		if !exists {
+			continue
 		}
 
-		if threshold.ThresholdUp24hEnabled && threshold.ThresholdUp24hPercent != nil {
-			alertsFound = uc.checkUserVarThresholdUp(threshold, data, "24h", data.PercentChange24h,
-				*threshold.ThresholdUp24hPercent, fearGreed, historicalData, &alerts) || alertsFound
-		}
-		if threshold.ThresholdDown24hEnabled && threshold.ThresholdDown24hPercent != nil {
-			alertsFound = uc.checkUserVarThresholdDown(threshold, data, "24h", data.PercentChange24h,
-				*threshold.ThresholdDown24hPercent, fearGreed, historicalData, &alerts) || alertsFound
-		}
+		historicalData := fullHistory.LastDays(defaultHistoryDays)
 
-		if threshold.ThresholdUp7dEnabled && threshold.ThresholdUp7dPercent != nil {
-			alertsFound = uc.checkUserVarThresholdUp(threshold, data, "7d", data.PercentChange7d,
-				*threshold.ThresholdUp7dPercent, fearGreed, historicalData, &alerts) || alertsFound
-		}
-		if threshold.ThresholdDown7dEnabled && threshold.ThresholdDown7dPercent != nil {
-			alertsFound = uc.checkUserVarThresholdDown(threshold, data, "7d", data.PercentChange7d,
-				*threshold.ThresholdDown7dPercent, fearGreed, historicalData, &alerts) || alertsFound
+		alertsFound := false
+		firstAlert := len(alerts)
+
+		crossDirection := ""
+		for _, rule := range threshold.Rules {
+			if !rule.Enabled {
+				continue
+			}
+
+			firstRuleAlert := len(alerts)
+			switch rule.Kind {
+			case entity.RuleKindWindow:
+				variation, ok := windowVariation(rule, data, fullHistory, hourlyHistory, now)
+				if !ok {
+					log.Printf("Warning: No %s variation available for %s", rule.Period, threshold.CryptoSymbol)
+					continue
+				}
+
+				if rule.Direction == entity.DirectionUp {
+					alertsFound = uc.checkUserVarThresholdUp(threshold, data, rule.Period, variation,
+						rule.Value, fearGreed, historicalData, &alerts) || alertsFound
+				} else {
+					alertsFound = uc.checkUserVarThresholdDown(threshold, data, rule.Period, variation,
+						rule.Value, fearGreed, historicalData, &alerts) || alertsFound
+				}
+
+			case entity.RuleKindTargetPrice:
+				crossed := false
+				if rule.Direction == entity.DirectionUp {
+					crossed = uc.checkUserTargetPriceUp(threshold, data, rule.Value, fearGreed, historicalData, &alerts)
+				} else {
+					crossed = uc.checkUserTargetPriceDown(threshold, data, rule.Value, fearGreed, historicalData, &alerts)
+				}
+				if crossed {
+					crossDirection = rule.Direction
+					alertsFound = true
+				}
+			}
+
+			for i := firstRuleAlert; i < len(alerts); i++ {
+				alerts[i].RuleID = rule.ID
+			}
 		}
 
-		if threshold.ThresholdUp30dEnabled && threshold.ThresholdUp30dPercent != nil {
-			alertsFound = uc.checkUserVarThresholdUp(threshold, data, "30d", data.PercentChange30d,
-				*threshold.ThresholdUp30dPercent, fearGreed, historicalData, &alerts) || alertsFound
-		}
-		if threshold.ThresholdDown30dEnabled && threshold.ThresholdDown30dPercent != nil {
-			alertsFound = uc.checkUserVarThresholdDown(threshold, data, "30d", data.PercentChange30d,
-				*threshold.ThresholdDown30dPercent, fearGreed, historicalData, &alerts) || alertsFound
+		if threshold.HasRangeAlerts() && fullHistory != nil {
+			lookbackHistory := fullHistory.LastDays(threshold.RangeLookbackDays)
+			if direction := uc.checkUserRangeAlerts(threshold, data, lookbackHistory, fearGreed, historicalData, &alerts); direction != "" {
+				crossDirection = direction
+				alertsFound = true
+			}
 		}
-
-		if threshold.ThresholdUp60dEnabled && threshold.ThresholdUp60dPercent != nil {
-			alertsFound = uc.checkUserVarThresholdUp(threshold, data, "60d", data.PercentChange60d,
-				*threshold.ThresholdUp60dPercent, fearGreed, historicalData, &alerts) || alertsFound
+		if threshold.VolatilityEnabled && threshold.VolatilityMultiple != nil && fullHistory != nil {
+			alertsFound = uc.checkUserVolatilityRegime(threshold, data, fullHistory, *threshold.VolatilityMultiple, fearGreed, historicalData, &alerts) || alertsFound
 		}
-		if threshold.ThresholdDown60dEnabled && threshold.ThresholdDown60dPercent != nil {
-			alertsFound = uc.checkUserVarThresholdDown(threshold, data, "60d", data.PercentChange60d,
-				*threshold.ThresholdDown60dPercent, fearGreed, historicalData, &alerts) || alertsFound
+		if threshold.TracksPriceCrossings() {
+			uc.recordPriceObservation(threshold, data.Price, crossDirection)
 		}
 
-		if threshold.ThresholdUp90dEnabled && threshold.ThresholdUp90dPercent != nil {
-			alertsFound = uc.checkUserVarThresholdUp(threshold, data, "90d", data.PercentChange90d,
-				*threshold.ThresholdUp90dPercent, fearGreed, historicalData, &alerts) || alertsFound
-		}
-		if threshold.ThresholdDown90dEnabled && threshold.ThresholdDown90dPercent != nil {
-			alertsFound = uc.checkUserVarThresholdDown(threshold, data, "90d", data.PercentChange90d,
-				*threshold.ThresholdDown90dPercent, fearGreed, historicalData, &alerts) || alertsFound
+		if threshold.CompositeRuleEnabled && threshold.CompositeRule != nil {
+			alertsFound = uc.checkUserCompositeRule(threshold, data, threshold.CompositeRule, fearGreed, historicalData, &alerts) || alertsFound
 		}
-
-		if threshold.TargetPriceUpEnabled && threshold.TargetPriceUp != nil {
-			alertsFound = uc.checkUserTargetPriceUp(threshold, data, *threshold.TargetPriceUp, fearGreed, historicalData, &alerts) || alertsFound
+		if threshold.CustomExpressionEnabled && threshold.CustomExpression != nil {
+			alertsFound = uc.checkUserCustomExpression(threshold, data, *threshold.CustomExpression, fearGreed, historicalData, &alerts) || alertsFound
 		}
-		if threshold.TargetPriceDownEnabled && threshold.TargetPriceDown != nil {
-			alertsFound = uc.checkUserTargetPriceDown(threshold, data, *threshold.TargetPriceDown, fearGreed, historicalData, &alerts) || alertsFound
+		if threshold.DepegEnabled && threshold.DepegBandBps != nil {
+			alertsFound = uc.checkUserDepeg(threshold, data, *threshold.DepegBandBps, fearGreed, historicalData, &alerts) || alertsFound
 		}
 
-		if !alertsFound {
+		if alertsFound && !uc.recordTrigger(threshold) {
+			alerts = alerts[:firstAlert]
+		} else if alertsFound {
+			for i := firstAlert; i < len(alerts); i++ {
+				alerts[i].Locale = threshold.Locale
+			}
+			if threshold.DigestFrequency == entity.DigestFrequencyNone {
+				for i := firstAlert; i < len(alerts); i++ {
+					alerts[i].Actions = uc.actionSigner.Links(threshold.ID, alerts[i].RuleID, threshold.Email, threshold.CryptoSymbol, threshold.Locale, now)
+				}
+				batch.add(threshold.ID, threshold.Email, threshold.Critical, alerts[firstAlert:]...)
+			} else {
+				for _, alert := range alerts[firstAlert:] {
+					recordAlertHistory(uc.historyRepo, uc.stream, newAlertHistory(threshold.ID, threshold.Email, alert))
+				}
+				log.Printf("%d alerts for %s kept for the %s digest of %s", len(alerts)-firstAlert,
+					threshold.CryptoSymbol, threshold.DigestFrequency, threshold.Email)
+			}
+		} else {
 			log.Printf("✓ No alerts for user %s - %s - all variations within thresholds",
 				threshold.Email, threshold.CryptoSymbol)
 		}
 	}
 
+	batch.send(uc.outboxRepo, uc.historyRepo, uc.settingsRepo, now)
+
 	return alerts
 }
 
+// resolveMarketData returns the quote and the daily and hourly histories a
+// threshold is evaluated against: the symbol itself in USD, or the base/quote
+// ratio for pair alerts.
+func resolveMarketData(
+	threshold *entity.AlertThreshold,
+	cryptoData map[string]*entity.CryptoCurrency,
+	historicalDataMap map[string]*entity.HistoricalPriceData,
+	hourlyDataMap map[string]*entity.HistoricalPriceData,
+) (*entity.CryptoCurrency, *entity.HistoricalPriceData, *entity.HistoricalPriceData, bool) {
+	data, exists := cryptoData[threshold.CryptoSymbol]
+	if !exists {
+		return nil, nil, nil, false
+	}
+
+	if threshold.QuoteSymbol == "" {
+		return data, historicalDataMap[threshold.CryptoSymbol], hourlyDataMap[threshold.CryptoSymbol], true
+	}
+
+	quote, exists := cryptoData[threshold.QuoteSymbol]
+	if !exists {
+		return nil, nil, nil, false
+	}
+
+	pairData := pkg.BuildPairQuote(data, quote)
+	if pairData == nil {
+		return nil, nil, nil, false
+	}
+
+	pairHistory := pkg.BuildPairHistory(historicalDataMap[threshold.CryptoSymbol], historicalDataMap[threshold.QuoteSymbol], 24*time.Hour)
+	pairHourlyHistory := pkg.BuildPairHistory(hourlyDataMap[threshold.CryptoSymbol], hourlyDataMap[threshold.QuoteSymbol], time.Hour)
+
+	return pairData, pairHistory, pairHourlyHistory, true
+}
+
+// windowVariation returns the percent change over the rule's window, taken
+// from the quote when CoinMarketCap precomputes it and from history otherwise.
+func windowVariation(
+	rule entity.AlertRule,
+	data *entity.CryptoCurrency,
+	dailyHistory *entity.HistoricalPriceData,
+	hourlyHistory *entity.HistoricalPriceData,
+	now time.Time,
+) (float64, bool) {
+	duration, err := entity.ParseWindow(rule.Period)
+	if err != nil {
+		return 0, false
+	}
+
+	switch rule.Source() {
+	case entity.WindowSourceQuote:
+		return rule.QuotePercentChange(data)
+	case entity.WindowSourceDailyHistory:
+		return pkg.PercentChangeOver(dailyHistory, data.Price, duration, dailyWindowTolerance, now)
+	default:
+		return pkg.PercentChangeOver(hourlyHistory, data.Price, duration, hourlyWindowTolerance, now)
+	}
+}
+
 func (uc *executeAlertScanUseCase) checkUserVarThresholdUp(
 	threshold *entity.AlertThreshold,
 	data *entity.CryptoCurrency,
@@ -186,7 +328,8 @@ func (uc *executeAlertScanUseCase) checkUserVarThresholdUp(
 	if variation >= thresholdValue {
 		alert := pkg.AlertMessage{
 			Name:           data.Name,
-			Symbol:         threshold.CryptoSymbol,
+			Symbol:         pkg.PairSymbol(threshold.CryptoSymbol, threshold.QuoteSymbol),
+			QuoteSymbol:    threshold.QuoteSymbol,
 			Price:          data.Price,
 			Volume:         data.Volume24h,
 			Period:         period,
@@ -203,8 +346,6 @@ func (uc *executeAlertScanUseCase) checkUserVarThresholdUp(
 
 		*alerts = append(*alerts, alert)
 
-		uc.sendAlertEmailToUser(threshold.Email, alert)
-
 		return true
 	}
 	return false
@@ -223,7 +364,8 @@ func (uc *executeAlertScanUseCase) checkUserVarThresholdDown(
 	if variation <= thresholdValue {
 		alert := pkg.AlertMessage{
 			Name:           data.Name,
-			Symbol:         threshold.CryptoSymbol,
+			Symbol:         pkg.PairSymbol(threshold.CryptoSymbol, threshold.QuoteSymbol),
+			QuoteSymbol:    threshold.QuoteSymbol,
 			Price:          data.Price,
 			Volume:         data.Volume24h,
 			Period:         period,
@@ -240,24 +382,11 @@ func (uc *executeAlertScanUseCase) checkUserVarThresholdDown(
 
 		*alerts = append(*alerts, alert)
 
-		uc.sendAlertEmailToUser(threshold.Email, alert)
-
 		return true
 	}
 	return false
 }
 
-func (uc *executeAlertScanUseCase) sendAlertEmailToUser(userEmail string, alert pkg.AlertMessage) {
-	subject := pkg.FormatEmailSubject(alert)
-	body := pkg.FormatEmailBody(alert)
-
-	if err := uc.notifier.SendEmailAlert(userEmail, subject, body); err != nil {
-		log.Printf("Failed to send email alert to %s: %v", userEmail, err)
-	} else {
-		log.Printf("Email alert sent to %s for %s %s %s", userEmail, alert.Symbol, alert.Period, alert.Direction)
-	}
-}
-
 func (uc *executeAlertScanUseCase) checkUserTargetPriceUp(
 	threshold *entity.AlertThreshold,
 	data *entity.CryptoCurrency,
@@ -266,10 +395,11 @@ func (uc *executeAlertScanUseCase) checkUserTargetPriceUp(
 	historicalData *entity.HistoricalPriceData,
 	alerts *[]pkg.AlertMessage,
 ) bool {
-	if data.Price >= targetPrice {
+	if crossedAbove(threshold.LastObservedPrice, data.Price, targetPrice) {
 		alert := pkg.AlertMessage{
 			Name:           data.Name,
-			Symbol:         threshold.CryptoSymbol,
+			Symbol:         pkg.PairSymbol(threshold.CryptoSymbol, threshold.QuoteSymbol),
+			QuoteSymbol:    threshold.QuoteSymbol,
 			Price:          data.Price,
 			Volume:         data.Volume24h,
 			Period:         "target",
@@ -278,6 +408,7 @@ func (uc *executeAlertScanUseCase) checkUserTargetPriceUp(
 			Direction:      "up",
 			IsTargetPrice:  true,
 			TargetPrice:    targetPrice,
+			PreviousPrice:  *threshold.LastObservedPrice,
 			HistoricalData: historicalData,
 		}
 
@@ -288,7 +419,6 @@ func (uc *executeAlertScanUseCase) checkUserTargetPriceUp(
 
 		*alerts = append(*alerts, alert)
 
-		uc.sendAlertEmailToUser(threshold.Email, alert)
 		return true
 	}
 	return false
@@ -302,10 +432,11 @@ func (uc *executeAlertScanUseCase) checkUserTargetPriceDown(
 	historicalData *entity.HistoricalPriceData,
 	alerts *[]pkg.AlertMessage,
 ) bool {
-	if data.Price <= targetPrice {
+	if crossedBelow(threshold.LastObservedPrice, data.Price, targetPrice) {
 		alert := pkg.AlertMessage{
 			Name:           data.Name,
-			Symbol:         threshold.CryptoSymbol,
+			Symbol:         pkg.PairSymbol(threshold.CryptoSymbol, threshold.QuoteSymbol),
+			QuoteSymbol:    threshold.QuoteSymbol,
 			Price:          data.Price,
 			Volume:         data.Volume24h,
 			Period:         "target",
@@ -314,6 +445,7 @@ func (uc *executeAlertScanUseCase) checkUserTargetPriceDown(
 			Direction:      "down",
 			IsTargetPrice:  true,
 			TargetPrice:    targetPrice,
+			PreviousPrice:  *threshold.LastObservedPrice,
 			HistoricalData: historicalData,
 		}
 
@@ -324,8 +456,411 @@ func (uc *executeAlertScanUseCase) checkUserTargetPriceDown(
 
 		*alerts = append(*alerts, alert)
 
-		uc.sendAlertEmailToUser(threshold.Email, alert)
 		return true
 	}
 	return false
 }
+
+// checkUserRangeAlerts compares the price with the high/low of the lookback
+// window, excluding the latest point since CoinGecko reports the live price
+// there. Like target prices they fire on the crossing, so a sustained new high
+// only alerts again when the price makes an even higher high. It returns the
+// direction of the crossing that fired, if any.
+func (uc *executeAlertScanUseCase) checkUserRangeAlerts(
+	threshold *entity.AlertThreshold,
+	data *entity.CryptoCurrency,
+	lookbackHistory *entity.HistoricalPriceData,
+	fearGreed *entity.FearGreedIndex,
+	historicalData *entity.HistoricalPriceData,
+	alerts *[]pkg.AlertMessage,
+) string {
+	if len(lookbackHistory.Prices) < 2 {
+		return ""
+	}
+
+	previousRange := &entity.HistoricalPriceData{Prices: lookbackHistory.Prices[:len(lookbackHistory.Prices)-1]}
+	previousRange.RefreshStats()
+	rangeHigh, rangeLow := previousRange.MaxPrice, previousRange.MinPrice
+
+	newAlert := func(kind string, direction string) pkg.AlertMessage {
+		alert := pkg.AlertMessage{
+			Name:              data.Name,
+			Symbol:            pkg.PairSymbol(threshold.CryptoSymbol, threshold.QuoteSymbol),
+			QuoteSymbol:       threshold.QuoteSymbol,
+			Price:             data.Price,
+			Volume:            data.Volume24h,
+			Period:            fmt.Sprintf("%dd", threshold.RangeLookbackDays),
+			Direction:         direction,
+			IsRange:           true,
+			RangeKind:         kind,
+			RangeLookbackDays: threshold.RangeLookbackDays,
+			RangeHigh:         rangeHigh,
+			RangeLow:          rangeLow,
+			DrawdownPercent:   (data.Price - rangeHigh) / rangeHigh * 100,
+			PreviousPrice:     *threshold.LastObservedPrice,
+			HistoricalData:    historicalData,
+		}
+
+		if fearGreed != nil {
+			alert.FearGreedValue = fearGreed.Value
+			alert.FearGreedClass = fearGreed.Classification
+		}
+
+		return alert
+	}
+
+	direction := ""
+
+	if threshold.NewHighEnabled && crossedAbove(threshold.LastObservedPrice, data.Price, rangeHigh) {
+		alert := newAlert(pkg.RangeKindNewHigh, "up")
+		*alerts = append(*alerts, alert)
+		direction = entity.DirectionUp
+	}
+
+	if threshold.NewLowEnabled && crossedBelow(threshold.LastObservedPrice, data.Price, rangeLow) {
+		alert := newAlert(pkg.RangeKindNewLow, "down")
+		*alerts = append(*alerts, alert)
+		direction = entity.DirectionDown
+	}
+
+	if threshold.DrawdownEnabled && threshold.DrawdownPercent != nil {
+		drawdownLevel := rangeHigh * (1 - *threshold.DrawdownPercent/100)
+		if crossedBelow(threshold.LastObservedPrice, data.Price, drawdownLevel) {
+			alert := newAlert(pkg.RangeKindDrawdown, "down")
+			alert.Threshold = -*threshold.DrawdownPercent
+			*alerts = append(*alerts, alert)
+			direction = entity.DirectionDown
+		}
+	}
+
+	return direction
+}
+
+// checkUserVolatilityRegime fires when short-window realized volatility
+// enters a regime above multiple times the long-window baseline. It fires
+// once per regime and re-arms when the ratio falls back below the multiple.
+func (uc *executeAlertScanUseCase) checkUserVolatilityRegime(
+	threshold *entity.AlertThreshold,
+	data *entity.CryptoCurrency,
+	fullHistory *entity.HistoricalPriceData,
+	multiple float64,
+	fearGreed *entity.FearGreedIndex,
+	historicalData *entity.HistoricalPriceData,
+	alerts *[]pkg.AlertMessage,
+) bool {
+	shortVolatility, ok := pkg.RealizedVolatility(fullHistory.Prices, threshold.VolatilityShortWindow)
+	if !ok {
+		return false
+	}
+	longVolatility, ok := pkg.RealizedVolatility(fullHistory.Prices, threshold.VolatilityLongWindow)
+	if !ok || longVolatility == 0 {
+		return false
+	}
+
+	ratio := shortVolatility / longVolatility
+	inRegime := ratio >= multiple
+	wasInRegime := threshold.VolatilityRegimeActive
+
+	if inRegime != wasInRegime {
+		threshold.VolatilityRegimeActive = inRegime
+		if err := uc.alertRepo.UpdateVolatilityRegime(threshold.ID, inRegime); err != nil {
+			log.Printf("Failed to update volatility regime for threshold %d: %v", threshold.ID, err)
+		}
+	}
+
+	if !inRegime || wasInRegime {
+		return false
+	}
+
+	shortRange, _ := pkg.AverageRangePercent(fullHistory.Prices, threshold.VolatilityShortWindow)
+	longRange, _ := pkg.AverageRangePercent(fullHistory.Prices, threshold.VolatilityLongWindow)
+
+	alert := pkg.AlertMessage{
+		Name:               data.Name,
+		Symbol:             pkg.PairSymbol(threshold.CryptoSymbol, threshold.QuoteSymbol),
+		QuoteSymbol:        threshold.QuoteSymbol,
+		Price:              data.Price,
+		Volume:             data.Volume24h,
+		Period:             fmt.Sprintf("%dd", threshold.VolatilityShortWindow),
+		Direction:          "volatility",
+		Threshold:          multiple,
+		IsVolatility:       true,
+		ShortWindow:        threshold.VolatilityShortWindow,
+		LongWindow:         threshold.VolatilityLongWindow,
+		ShortVolatility:    shortVolatility,
+		LongVolatility:     longVolatility,
+		ShortRangePercent:  shortRange,
+		LongRangePercent:   longRange,
+		VolatilityMultiple: ratio,
+		HistoricalData:     historicalData,
+	}
+
+	if fearGreed != nil {
+		alert.FearGreedValue = fearGreed.Value
+		alert.FearGreedClass = fearGreed.Classification
+	}
+
+	*alerts = append(*alerts, alert)
+
+	return true
+}
+
+// crossedAbove reports whether the price moved from below the level on the
+// previous scan to at or above it on this one. Without a previous observation
+// there is nothing to compare against, so the first scan never fires.
+func crossedAbove(previous *float64, current float64, level float64) bool {
+	return previous != nil && *previous < level && current >= level
+}
+
+func crossedBelow(previous *float64, current float64, level float64) bool {
+	return previous != nil && *previous > level && current <= level
+}
+
+func (uc *executeAlertScanUseCase) recordPriceObservation(threshold *entity.AlertThreshold, price float64, crossDirection string) {
+	if err := uc.alertRepo.UpdatePriceObservation(threshold.ID, price, crossDirection); err != nil {
+		log.Printf("Failed to record price observation for threshold %d: %v", threshold.ID, err)
+	}
+}
+
+// isEvaluable applies the lifecycle rules before a threshold is checked,
+// expiring it when its expiry date has passed.
+func (uc *executeAlertScanUseCase) isEvaluable(threshold *entity.AlertThreshold, now time.Time) bool {
+	if threshold.State != entity.AlertStateActive {
+		return false
+	}
+
+	if threshold.ExpiresAt != nil && !now.Before(*threshold.ExpiresAt) {
+		if err := uc.alertRepo.RecordTrigger(threshold.ID, threshold.TriggerCount, entity.AlertStateExpired); err != nil {
+			log.Printf("Failed to expire threshold %d: %v", threshold.ID, err)
+			return false
+		}
+		threshold.State = entity.AlertStateExpired
+		log.Printf("Threshold %d for %s - %s expired", threshold.ID, threshold.Email, threshold.CryptoSymbol)
+		return false
+	}
+
+	if threshold.StartsAt != nil && now.Before(*threshold.StartsAt) {
+		return false
+	}
+
+	if threshold.SnoozedUntil != nil && now.Before(*threshold.SnoozedUntil) {
+		return false
+	}
+
+	return true
+}
+
+// recordTrigger counts the trigger and reports whether its alerts should be
+// sent, which is not the case when the threshold stopped being active while
+// the scan was running.
+func (uc *executeAlertScanUseCase) recordTrigger(threshold *entity.AlertThreshold) bool {
+	threshold.TriggerCount++
+	if threshold.IsExhausted() {
+		threshold.State = entity.AlertStateTriggered
+	}
+
+	err := uc.alertRepo.RecordTrigger(threshold.ID, threshold.TriggerCount, threshold.State)
+	if errors.Is(err, dbRepo.ErrInvalidStateTransition) {
+		log.Printf("Threshold %d changed during the scan, dropping its alerts: %v", threshold.ID, err)
+		return false
+	}
+	if err != nil {
+		log.Printf("Failed to record trigger for threshold %d: %v", threshold.ID, err)
+	}
+
+	return true
+}
+
+func (uc *executeAlertScanUseCase) checkUserCustomExpression(
+	threshold *entity.AlertThreshold,
+	data *entity.CryptoCurrency,
+	source string,
+	fearGreed *entity.FearGreedIndex,
+	historicalData *entity.HistoricalPriceData,
+	alerts *[]pkg.AlertMessage,
+) bool {
+	program, err := expression.Compile(source, entity.ConditionMetrics)
+	if err != nil {
+		log.Printf("Invalid custom expression for threshold %d: %v", threshold.ID, err)
+		return false
+	}
+
+	values := alertVariables(data, historicalData, fearGreed)
+	matched, err := program.Eval(values)
+	if err != nil {
+		log.Printf("Could not evaluate custom expression for threshold %d: %v", threshold.ID, err)
+		return false
+	}
+	if !matched {
+		return false
+	}
+
+	expressionValues := make(map[string]float64, len(program.Variables()))
+	for _, name := range program.Variables() {
+		expressionValues[name] = values[name]
+	}
+
+	alert := pkg.AlertMessage{
+		Name:             data.Name,
+		Symbol:           pkg.PairSymbol(threshold.CryptoSymbol, threshold.QuoteSymbol),
+		QuoteSymbol:      threshold.QuoteSymbol,
+		Price:            data.Price,
+		Volume:           data.Volume24h,
+		Period:           "expression",
+		Direction:        "expression",
+		IsExpression:     true,
+		Expression:       program.String(),
+		ExpressionValues: expressionValues,
+		HistoricalData:   historicalData,
+	}
+
+	if fearGreed != nil {
+		alert.FearGreedValue = fearGreed.Value
+		alert.FearGreedClass = fearGreed.Classification
+	}
+
+	*alerts = append(*alerts, alert)
+
+	return true
+}
+
+// checkUserDepeg counts consecutive scans outside the peg band and fires once
+// per depeg episode, when the count reaches the configured sustained scans.
+func (uc *executeAlertScanUseCase) checkUserDepeg(
+	threshold *entity.AlertThreshold,
+	data *entity.CryptoCurrency,
+	bandBps float64,
+	fearGreed *entity.FearGreedIndex,
+	historicalData *entity.HistoricalPriceData,
+	alerts *[]pkg.AlertMessage,
+) bool {
+	pegPrice := entity.DefaultPegPrice
+	if threshold.DepegPegPrice != nil {
+		pegPrice = *threshold.DepegPegPrice
+	}
+	sustainedScans := threshold.DepegSustainedScans
+	if sustainedScans < 1 {
+		sustainedScans = 1
+	}
+
+	deviationBps := (data.Price - pegPrice) / pegPrice * 10000
+
+	previousScans := threshold.DepegConsecutiveScans
+	if math.Abs(deviationBps) > bandBps {
+		threshold.DepegConsecutiveScans++
+	} else {
+		threshold.DepegConsecutiveScans = 0
+	}
+
+	if threshold.DepegConsecutiveScans != previousScans {
+		if err := uc.alertRepo.UpdateDepegConsecutiveScans(threshold.ID, threshold.DepegConsecutiveScans); err != nil {
+			log.Printf("Failed to update depeg counter for threshold %d: %v", threshold.ID, err)
+		}
+	}
+
+	if threshold.DepegConsecutiveScans != sustainedScans {
+		return false
+	}
+
+	direction := "up"
+	if deviationBps < 0 {
+		direction = "down"
+	}
+
+	alert := pkg.AlertMessage{
+		Name:           data.Name,
+		Symbol:         threshold.CryptoSymbol,
+		Price:          data.Price,
+		Volume:         data.Volume24h,
+		Period:         "depeg",
+		Direction:      direction,
+		IsDepeg:        true,
+		PegPrice:       pegPrice,
+		DeviationBps:   deviationBps,
+		BandBps:        bandBps,
+		SustainedScans: threshold.DepegConsecutiveScans,
+		HistoricalData: historicalData,
+	}
+
+	if fearGreed != nil {
+		alert.FearGreedValue = fearGreed.Value
+		alert.FearGreedClass = fearGreed.Classification
+	}
+
+	*alerts = append(*alerts, alert)
+
+	return true
+}
+
+func (uc *executeAlertScanUseCase) checkUserCompositeRule(
+	threshold *entity.AlertThreshold,
+	data *entity.CryptoCurrency,
+	rule *entity.Condition,
+	fearGreed *entity.FearGreedIndex,
+	historicalData *entity.HistoricalPriceData,
+	alerts *[]pkg.AlertMessage,
+) bool {
+	matched, results := rule.Evaluate(alertVariables(data, historicalData, fearGreed))
+	if !matched {
+		return false
+	}
+
+	alert := pkg.AlertMessage{
+		Name:             data.Name,
+		Symbol:           pkg.PairSymbol(threshold.CryptoSymbol, threshold.QuoteSymbol),
+		QuoteSymbol:      threshold.QuoteSymbol,
+		Price:            data.Price,
+		Volume:           data.Volume24h,
+		Period:           "composite",
+		Direction:        "composite",
+		IsComposite:      true,
+		CompositeRule:    rule,
+		ConditionResults: results,
+		HistoricalData:   historicalData,
+	}
+
+	if fearGreed != nil {
+		alert.FearGreedValue = fearGreed.Value
+		alert.FearGreedClass = fearGreed.Classification
+	}
+
+	*alerts = append(*alerts, alert)
+
+	return true
+}
+
+// alertVariables binds the values that composite rules and custom
+// expressions can reference. Historical and fear & greed variables are only
+// present when that data could be fetched.
+func alertVariables(data *entity.CryptoCurrency, historicalData *entity.HistoricalPriceData, fearGreed *entity.FearGreedIndex) map[string]float64 {
+	values := map[string]float64{
+		entity.MetricPrice:              data.Price,
+		entity.MetricVolume24h:          data.Volume24h,
+		entity.MetricVolumeChange24h:    data.VolumeChange24h,
+		entity.MetricMarketCap:          data.MarketCap,
+		entity.MetricMarketCapDominance: data.MarketCapDominance,
+		entity.MetricPctChange1h:        data.PercentChange1h,
+		entity.MetricPctChange24h:       data.PercentChange24h,
+		entity.MetricPctChange7d:        data.PercentChange7d,
+		entity.MetricPctChange30d:       data.PercentChange30d,
+		entity.MetricPctChange60d:       data.PercentChange60d,
+		entity.MetricPctChange90d:       data.PercentChange90d,
+	}
+
+	if historicalData != nil && len(historicalData.Prices) > 0 {
+		values[entity.MetricMinPrice90d] = historicalData.MinPrice
+		values[entity.MetricMaxPrice90d] = historicalData.MaxPrice
+		values[entity.MetricAvgPrice90d] = historicalData.AvgPrice
+	}
+	if historicalData != nil && len(historicalData.Volumes) > 0 {
+		values[entity.MetricMinVolume90d] = historicalData.MinVolume
+		values[entity.MetricMaxVolume90d] = historicalData.MaxVolume
+		values[entity.MetricAvgVolume90d] = historicalData.AvgVolume
+	}
+
+	if fearGreed != nil {
+		values[entity.MetricFearGreedIndex] = float64(fearGreed.Value)
+	}
+
+	return values
+}
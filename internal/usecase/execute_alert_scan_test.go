@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+	"fmt"
+	"testing"
+	"time"
+)
+
+// scanAlertRepo keeps the state of every threshold as stored, which may
+// differ from the copy the scan read.
+type scanAlertRepo struct {
+	db.AlertThresholdRepository
+	states map[int64]string
+}
+
+func (r *scanAlertRepo) RecordTrigger(id int64, triggerCount int, state string) error {
+	if r.states[id] != entity.AlertStateActive {
+		return fmt.Errorf("%w: threshold %d não está mais ativo", db.ErrInvalidStateTransition, id)
+	}
+	r.states[id] = state
+	return nil
+}
+
+func (r *scanAlertRepo) UpdatePriceObservation(id int64, price float64, crossDirection string) error {
+	return nil
+}
+
+func TestProcessAlertsSkipsThresholdsChangedDuringScan(t *testing.T) {
+	previous := 90.0
+	expired := time.Now().Add(-time.Hour)
+	target := func(id int64, email string) *entity.AlertThreshold {
+		return &entity.AlertThreshold{
+			ID:                id,
+			Email:             email,
+			CryptoSymbol:      "BTC",
+			State:             entity.AlertStateActive,
+			LastObservedPrice: &previous,
+			Rules:             []entity.AlertRule{{Kind: entity.RuleKindTargetPrice, Direction: entity.DirectionUp, Value: 100, Enabled: true}},
+		}
+	}
+
+	thresholds := []*entity.AlertThreshold{
+		target(1, "active@example.com"),
+		target(2, "paused@example.com"),
+		target(3, "expiring@example.com"),
+	}
+	thresholds[2].ExpiresAt = &expired
+
+	alertRepo := &scanAlertRepo{states: map[int64]string{
+		1: entity.AlertStateActive,
+		// paused by their users after the scan loaded them
+		2: entity.AlertStatePaused,
+		3: entity.AlertStatePaused,
+	}}
+	outbox := &outboxRecorder{}
+	uc := &executeAlertScanUseCase{
+		alertRepo:    alertRepo,
+		settingsRepo: digestSettingsRepo{},
+		outboxRepo:   outbox,
+		stream:       pkg.NewAlertStream(),
+	}
+
+	cryptoData := map[string]*entity.CryptoCurrency{"BTC": {Name: "Bitcoin", Price: 110}}
+	alerts := uc.processAlerts(thresholds, cryptoData, nil, nil, nil)
+
+	if len(alerts) != 1 || len(outbox.emails) != 1 || outbox.emails[0] != "active@example.com" {
+		t.Fatalf("sent %d alerts to %v, want only the one of the active threshold", len(alerts), outbox.emails)
+	}
+	if alertRepo.states[2] != entity.AlertStatePaused {
+		t.Errorf("paused threshold was overwritten with %s", alertRepo.states[2])
+	}
+	if alertRepo.states[3] != entity.AlertStatePaused || thresholds[2].State == entity.AlertStateExpired {
+		t.Errorf("paused threshold was expired (stored %s, scanned %s)", alertRepo.states[3], thresholds[2].State)
+	}
+}
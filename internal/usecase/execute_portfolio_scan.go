@@ -0,0 +1,242 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	apiRepo "crypto-alerts/internal/repository/api"
+	dbRepo "crypto-alerts/internal/repository/db"
+	"log"
+	"time"
+)
+
+type ExecutePortfolioScanUseCase interface {
+	Execute() ([]pkg.AlertMessage, error)
+}
+
+type executePortfolioScanUseCase struct {
+	holdingRepo       dbRepo.HoldingRepository
+	historyRepo       dbRepo.AlertHistoryRepository
+	settingsRepo      dbRepo.NotificationSettingsRepository
+	coinMarketCapRepo apiRepo.CoinMarketCapRepository
+	outboxRepo        dbRepo.OutboxRepository
+	emailVerifier     *EmailVerifier
+	stream            *pkg.AlertStream
+	groupBySymbol     bool
+}
+
+func NewExecutePortfolioScanUseCase(
+	holdingRepo dbRepo.HoldingRepository,
+	historyRepo dbRepo.AlertHistoryRepository,
+	settingsRepo dbRepo.NotificationSettingsRepository,
+	coinMarketCapRepo apiRepo.CoinMarketCapRepository,
+	outboxRepo dbRepo.OutboxRepository,
+	emailVerifier *EmailVerifier,
+	stream *pkg.AlertStream,
+	groupBySymbol bool,
+) ExecutePortfolioScanUseCase {
+	return &executePortfolioScanUseCase{
+		holdingRepo:       holdingRepo,
+		historyRepo:       historyRepo,
+		settingsRepo:      settingsRepo,
+		coinMarketCapRepo: coinMarketCapRepo,
+		outboxRepo:        outboxRepo,
+		emailVerifier:     emailVerifier,
+		stream:            stream,
+		groupBySymbol:     groupBySymbol,
+	}
+}
+
+func (uc *executePortfolioScanUseCase) Execute() ([]pkg.AlertMessage, error) {
+	holdings, err := uc.holdingRepo.GetAllHoldings()
+	if err != nil {
+		log.Printf("Error getting holdings from database: %v", err)
+		return nil, err
+	}
+
+	if len(holdings) == 0 {
+		log.Println("No holdings found in database")
+		return []pkg.AlertMessage{}, nil
+	}
+
+	portfolioAlerts, err := uc.holdingRepo.GetPortfolioAlerts()
+	if err != nil {
+		log.Printf("Error getting portfolio alerts from database: %v", err)
+		return nil, err
+	}
+
+	holdingsByEmail := make(map[string][]*entity.Holding)
+	symbolSet := make(map[string]bool)
+	for _, holding := range holdings {
+		holdingsByEmail[holding.Email] = append(holdingsByEmail[holding.Email], holding)
+		symbolSet[holding.CryptoSymbol] = true
+	}
+
+	symbols := make([]string, 0, len(symbolSet))
+	for symbol := range symbolSet {
+		symbols = append(symbols, symbol)
+	}
+
+	cryptoData, err := uc.coinMarketCapRepo.GetCryptoPrices(symbols)
+	if err != nil {
+		log.Printf("Error getting crypto prices: %v", err)
+		return nil, err
+	}
+
+	var alerts []pkg.AlertMessage
+	batch := newAlertBatch(uc.groupBySymbol, uc.stream)
+	verified := uc.verifiedEmails()
+
+	for _, holding := range holdings {
+		data, exists := cryptoData[holding.CryptoSymbol]
+		if !exists {
+			continue
+		}
+
+		if holding.HasPnLAlerts() {
+			if verified(holding.Email) {
+				firstAlert := len(alerts)
+				uc.checkHoldingPnL(holding, data, &alerts)
+				batch.add(0, holding.Email, false, alerts[firstAlert:]...)
+			}
+			if err := uc.holdingRepo.UpdateHoldingObservation(holding.ID, data.Price); err != nil {
+				log.Printf("Failed to record price observation for holding %d: %v", holding.ID, err)
+			}
+		}
+	}
+
+	for _, portfolioAlert := range portfolioAlerts {
+		userHoldings, exists := holdingsByEmail[portfolioAlert.Email]
+		if !exists {
+			continue
+		}
+
+		portfolio := entity.NewPortfolio(portfolioAlert.Email, userHoldings, cryptoData)
+		if verified(portfolioAlert.Email) {
+			firstAlert := len(alerts)
+			uc.checkPortfolioValue(portfolioAlert, portfolio, &alerts)
+			batch.add(0, portfolioAlert.Email, false, alerts[firstAlert:]...)
+		}
+		if err := uc.holdingRepo.UpdatePortfolioObservation(portfolioAlert.Email, portfolio.TotalValue); err != nil {
+			log.Printf("Failed to record portfolio value for %s: %v", portfolioAlert.Email, err)
+		}
+	}
+
+	batch.send(uc.outboxRepo, uc.historyRepo, uc.settingsRepo, time.Now())
+
+	log.Printf("Processed %d holdings and generated %d portfolio alerts", len(holdings), len(alerts))
+
+	return alerts, nil
+}
+
+// verifiedEmails returns a lookup of whether an address may be mailed,
+// remembering each answer for the scan. Addresses whose state can't be read
+// are not mailed. The price observations of unconfirmed addresses are still
+// recorded, so confirming doesn't fire on an old crossing.
+func (uc *executePortfolioScanUseCase) verifiedEmails() func(email string) bool {
+	verified := make(map[string]bool)
+	return func(email string) bool {
+		if result, ok := verified[email]; ok {
+			return result
+		}
+		result, err := uc.emailVerifier.isVerified(email)
+		if err != nil {
+			log.Printf("Error checking whether %s is confirmed: %v", email, err)
+		}
+		verified[email] = result
+		return result
+	}
+}
+
+// checkHoldingPnL converts each P&L threshold into the price at which it is
+// reached, so a threshold fires once when the price crosses it rather than on
+// every scan the position stays beyond it.
+func (uc *executePortfolioScanUseCase) checkHoldingPnL(
+	holding *entity.Holding,
+	data *entity.CryptoCurrency,
+	alerts *[]pkg.AlertMessage,
+) {
+	position := entity.NewPosition(holding, data.Price)
+
+	type pnlLevel struct {
+		kind      string
+		threshold *float64
+		price     func(float64) float64
+	}
+
+	levels := []pnlLevel{
+		{pkg.PnLKindPercent, holding.PnLPercentUp, holding.PriceForPnLPercent},
+		{pkg.PnLKindPercent, holding.PnLPercentDown, holding.PriceForPnLPercent},
+		{pkg.PnLKindAmount, holding.PnLAmountUp, holding.PriceForPnLAmount},
+		{pkg.PnLKindAmount, holding.PnLAmountDown, holding.PriceForPnLAmount},
+	}
+
+	for _, level := range levels {
+		if level.threshold == nil {
+			continue
+		}
+
+		levelPrice := level.price(*level.threshold)
+		direction := ""
+		if *level.threshold > 0 && crossedAbove(holding.LastObservedPrice, data.Price, levelPrice) {
+			direction = "up"
+		}
+		if *level.threshold < 0 && crossedBelow(holding.LastObservedPrice, data.Price, levelPrice) {
+			direction = "down"
+		}
+		if direction == "" {
+			continue
+		}
+
+		alert := pkg.AlertMessage{
+			Name:          data.Name,
+			Symbol:        holding.CryptoSymbol,
+			Price:         data.Price,
+			Volume:        data.Volume24h,
+			Period:        "pnl",
+			Threshold:     *level.threshold,
+			Direction:     direction,
+			PreviousPrice: *holding.LastObservedPrice,
+			IsPnL:         true,
+			PnLKind:       level.kind,
+			Quantity:      holding.Quantity,
+			EntryPrice:    holding.AverageEntryPrice,
+			PositionValue: position.Value,
+			CostBasis:     holding.CostBasis(),
+			PnLAmount:     position.PnLAmount,
+			PnLPercent:    position.PnLPercent,
+			Locale:        holding.Locale,
+		}
+
+		*alerts = append(*alerts, alert)
+	}
+}
+
+func (uc *executePortfolioScanUseCase) checkPortfolioValue(
+	portfolioAlert *entity.PortfolioAlert,
+	portfolio *entity.Portfolio,
+	alerts *[]pkg.AlertMessage,
+) {
+	newAlert := func(threshold float64, direction string) pkg.AlertMessage {
+		return pkg.AlertMessage{
+			Symbol:         "PORTFOLIO",
+			Period:         "portfolio",
+			Threshold:      threshold,
+			Direction:      direction,
+			IsPortfolio:    true,
+			PortfolioValue: portfolio.TotalValue,
+			PortfolioCost:  portfolio.TotalCost,
+			Positions:      portfolio.Positions,
+			Locale:         portfolioAlert.Locale,
+		}
+	}
+
+	if portfolioAlert.ValueAbove != nil && crossedAbove(portfolioAlert.LastObservedValue, portfolio.TotalValue, *portfolioAlert.ValueAbove) {
+		alert := newAlert(*portfolioAlert.ValueAbove, "up")
+		*alerts = append(*alerts, alert)
+	}
+
+	if portfolioAlert.ValueBelow != nil && crossedBelow(portfolioAlert.LastObservedValue, portfolio.TotalValue, *portfolioAlert.ValueBelow) {
+		alert := newAlert(*portfolioAlert.ValueBelow, "down")
+		*alerts = append(*alerts, alert)
+	}
+}
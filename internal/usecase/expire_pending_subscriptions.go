@@ -0,0 +1,37 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/repository/db"
+	"log"
+	"time"
+)
+
+type ExpirePendingSubscriptionsUseCase interface {
+	Execute(now time.Time) (int, error)
+}
+
+type expirePendingSubscriptionsUseCase struct {
+	alertRepo     db.AlertThresholdRepository
+	pendingExpiry time.Duration
+}
+
+func NewExpirePendingSubscriptionsUseCase(alertRepo db.AlertThresholdRepository, pendingExpiry time.Duration) ExpirePendingSubscriptionsUseCase {
+	return &expirePendingSubscriptionsUseCase{
+		alertRepo:     alertRepo,
+		pendingExpiry: pendingExpiry,
+	}
+}
+
+func (uc *expirePendingSubscriptionsUseCase) Execute(now time.Time) (int, error) {
+	expired, err := uc.alertRepo.ExpirePending(now.Add(-uc.pendingExpiry))
+	if err != nil {
+		log.Printf("Error expiring pending subscriptions: %v", err)
+		return 0, err
+	}
+
+	if expired > 0 {
+		log.Printf("Expired %d subscriptions never confirmed within %s", expired, uc.pendingExpiry)
+	}
+
+	return expired, nil
+}
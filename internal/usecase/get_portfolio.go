@@ -0,0 +1,59 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	apiRepo "crypto-alerts/internal/repository/api"
+	"crypto-alerts/internal/repository/db"
+	"fmt"
+)
+
+type GetPortfolioUseCase interface {
+	Execute(email string) (*entity.Portfolio, error)
+}
+
+type getPortfolioUseCase struct {
+	holdingRepo       db.HoldingRepository
+	coinMarketCapRepo apiRepo.CoinMarketCapRepository
+}
+
+func NewGetPortfolioUseCase(holdingRepo db.HoldingRepository, coinMarketCapRepo apiRepo.CoinMarketCapRepository) GetPortfolioUseCase {
+	return &getPortfolioUseCase{
+		holdingRepo:       holdingRepo,
+		coinMarketCapRepo: coinMarketCapRepo,
+	}
+}
+
+func (uc *getPortfolioUseCase) Execute(email string) (*entity.Portfolio, error) {
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+
+	holdings, err := uc.holdingRepo.GetHoldingsByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(holdings) == 0 {
+		return entity.NewPortfolio(email, nil, nil), nil
+	}
+
+	symbols := make([]string, 0, len(holdings))
+	for _, holding := range holdings {
+		symbols = append(symbols, holding.CryptoSymbol)
+	}
+
+	prices, err := uc.coinMarketCapRepo.GetCryptoPrices(symbols)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prices are returned with the precision emails show them with, values
+	// having been computed from the exact ones.
+	portfolio := entity.NewPortfolio(email, holdings, prices)
+	for i := range portfolio.Positions {
+		portfolio.Positions[i].Price = pkg.RoundPrice(portfolio.Positions[i].Price)
+	}
+
+	return portfolio, nil
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/repository/db"
+	"fmt"
+)
+
+type ListAlertsUseCase interface {
+	Execute(email string) ([]*entity.AlertThreshold, error)
+}
+
+type listAlertsUseCase struct {
+	alertRepo db.AlertThresholdRepository
+}
+
+func NewListAlertsUseCase(alertRepo db.AlertThresholdRepository) ListAlertsUseCase {
+	return &listAlertsUseCase{
+		alertRepo: alertRepo,
+	}
+}
+
+func (uc *listAlertsUseCase) Execute(email string) ([]*entity.AlertThreshold, error) {
+	if email == "" {
+		return nil, fmt.Errorf("email is required")
+	}
+
+	thresholds, err := uc.alertRepo.GetThresholdsByEmail(email)
+	if err != nil {
+		return nil, err
+	}
+
+	if thresholds == nil {
+		thresholds = []*entity.AlertThreshold{}
+	}
+
+	return thresholds, nil
+}
@@ -0,0 +1,43 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/repository/db"
+	"log"
+	"time"
+)
+
+// deadLetterListLimit caps the dead letters returned for inspection.
+const deadLetterListLimit = 200
+
+type RedriveOutboxUseCase interface {
+	ListDead() ([]*entity.OutboxMessage, error)
+	Execute(ids []int64) (int, error)
+}
+
+type redriveOutboxUseCase struct {
+	outboxRepo db.OutboxRepository
+}
+
+func NewRedriveOutboxUseCase(outboxRepo db.OutboxRepository) RedriveOutboxUseCase {
+	return &redriveOutboxUseCase{
+		outboxRepo: outboxRepo,
+	}
+}
+
+func (uc *redriveOutboxUseCase) ListDead() ([]*entity.OutboxMessage, error) {
+	return uc.outboxRepo.GetDead(deadLetterListLimit)
+}
+
+// Execute re-queues the given dead letters, or all of them when ids is
+// empty, for immediate delivery.
+func (uc *redriveOutboxUseCase) Execute(ids []int64) (int, error) {
+	redriven, err := uc.outboxRepo.Redrive(ids, time.Now())
+	if err != nil {
+		return 0, err
+	}
+
+	log.Printf("Re-drove %d dead-lettered emails", redriven)
+
+	return redriven, nil
+}
@@ -0,0 +1,98 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+	"fmt"
+	"strings"
+)
+
+type SaveHoldingUseCase interface {
+	Execute(holding *entity.Holding) error
+}
+
+type saveHoldingUseCase struct {
+	holdingRepo   db.HoldingRepository
+	emailVerifier *EmailVerifier
+}
+
+// NewSaveHoldingUseCase saves holdings of unconfirmed addresses too, but
+// their P&L alerts are only mailed once the address is confirmed.
+func NewSaveHoldingUseCase(holdingRepo db.HoldingRepository, emailVerifier *EmailVerifier) SaveHoldingUseCase {
+	return &saveHoldingUseCase{
+		holdingRepo:   holdingRepo,
+		emailVerifier: emailVerifier,
+	}
+}
+
+func (uc *saveHoldingUseCase) Execute(holding *entity.Holding) error {
+	holding.CryptoSymbol = strings.ToUpper(strings.TrimSpace(holding.CryptoSymbol))
+
+	if err := uc.validate(holding); err != nil {
+		return err
+	}
+
+	locale, err := subscriptionLocale(holding.Locale)
+	if err != nil {
+		return err
+	}
+	holding.Locale = locale
+
+	verified, err := uc.emailVerifier.isVerified(holding.Email)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.holdingRepo.SaveHolding(holding); err != nil {
+		return err
+	}
+
+	if !verified && holding.HasPnLAlerts() {
+		uc.emailVerifier.requestConfirmation(holding.Email, holding.CryptoSymbol, holding.Locale)
+	}
+
+	return nil
+}
+
+func (uc *saveHoldingUseCase) validate(holding *entity.Holding) error {
+	if holding.Email == "" {
+		return fmt.Errorf("email is required")
+	}
+	if holding.CryptoSymbol == "" {
+		return fmt.Errorf("crypto symbol is required")
+	}
+	if holding.Quantity <= 0 {
+		return fmt.Errorf("quantity must be positive")
+	}
+	if holding.AverageEntryPrice <= 0 {
+		return fmt.Errorf("average entry price must be positive")
+	}
+
+	if holding.PnLPercentUp != nil && *holding.PnLPercentUp <= 0 {
+		return fmt.Errorf("pnl percent up must be positive")
+	}
+	if holding.PnLPercentDown != nil && (*holding.PnLPercentDown >= 0 || *holding.PnLPercentDown <= -100) {
+		return fmt.Errorf("pnl percent down must be between -100 and 0")
+	}
+	if holding.PnLAmountUp != nil && *holding.PnLAmountUp <= 0 {
+		return fmt.Errorf("pnl amount up must be positive")
+	}
+	if holding.PnLAmountDown != nil && (*holding.PnLAmountDown >= 0 || -*holding.PnLAmountDown >= holding.CostBasis()) {
+		return fmt.Errorf("pnl amount down must be negative and smaller than the cost basis")
+	}
+
+	return nil
+}
+
+// subscriptionLocale defaults an empty locale and maps supported tags such as
+// "en-US" onto the locale they stand for.
+func subscriptionLocale(tag string) (string, error) {
+	if strings.TrimSpace(tag) == "" {
+		return pkg.DefaultLocale, nil
+	}
+	if locale := pkg.NormalizeLocale(tag); locale != "" {
+		return locale, nil
+	}
+	return "", fmt.Errorf("locale must be %s, %s or %s", pkg.LocalePortuguese, pkg.LocaleEnglish, pkg.LocaleSpanish)
+}
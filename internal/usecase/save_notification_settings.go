@@ -0,0 +1,51 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/repository/db"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type SaveNotificationSettingsUseCase interface {
+	Execute(settings *entity.NotificationSettings) error
+}
+
+type saveNotificationSettingsUseCase struct {
+	settingsRepo db.NotificationSettingsRepository
+}
+
+func NewSaveNotificationSettingsUseCase(settingsRepo db.NotificationSettingsRepository) SaveNotificationSettingsUseCase {
+	return &saveNotificationSettingsUseCase{
+		settingsRepo: settingsRepo,
+	}
+}
+
+func (uc *saveNotificationSettingsUseCase) Execute(settings *entity.NotificationSettings) error {
+	settings.TimeZone = strings.TrimSpace(settings.TimeZone)
+	settings.QuietHoursStart = strings.TrimSpace(settings.QuietHoursStart)
+	settings.QuietHoursEnd = strings.TrimSpace(settings.QuietHoursEnd)
+
+	if settings.Email == "" {
+		return fmt.Errorf("email is required")
+	}
+	if settings.TimeZone != "" {
+		if _, err := time.LoadLocation(settings.TimeZone); err != nil {
+			return fmt.Errorf("unknown time zone %q", settings.TimeZone)
+		}
+	}
+	if (settings.QuietHoursStart == "") != (settings.QuietHoursEnd == "") {
+		return fmt.Errorf("quiet hours start and end must be set together")
+	}
+	if settings.QuietHoursStart != "" {
+		if _, err := entity.ParseClock(settings.QuietHoursStart); err != nil {
+			return fmt.Errorf("quiet hours start: %w", err)
+		}
+		if _, err := entity.ParseClock(settings.QuietHoursEnd); err != nil {
+			return fmt.Errorf("quiet hours end: %w", err)
+		}
+	}
+
+	return uc.settingsRepo.Save(settings)
+}
@@ -0,0 +1,62 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/repository/db"
+	"fmt"
+)
+
+type SavePortfolioAlertUseCase interface {
+	Execute(alert *entity.PortfolioAlert) error
+}
+
+type savePortfolioAlertUseCase struct {
+	holdingRepo   db.HoldingRepository
+	emailVerifier *EmailVerifier
+}
+
+func NewSavePortfolioAlertUseCase(holdingRepo db.HoldingRepository, emailVerifier *EmailVerifier) SavePortfolioAlertUseCase {
+	return &savePortfolioAlertUseCase{
+		holdingRepo:   holdingRepo,
+		emailVerifier: emailVerifier,
+	}
+}
+
+func (uc *savePortfolioAlertUseCase) Execute(alert *entity.PortfolioAlert) error {
+	if alert.Email == "" {
+		return fmt.Errorf("email is required")
+	}
+	if alert.ValueAbove == nil && alert.ValueBelow == nil {
+		return fmt.Errorf("value above or value below is required")
+	}
+	if alert.ValueAbove != nil && *alert.ValueAbove <= 0 {
+		return fmt.Errorf("value above must be positive")
+	}
+	if alert.ValueBelow != nil && *alert.ValueBelow <= 0 {
+		return fmt.Errorf("value below must be positive")
+	}
+	if alert.ValueAbove != nil && alert.ValueBelow != nil && *alert.ValueBelow >= *alert.ValueAbove {
+		return fmt.Errorf("value below must be lower than value above")
+	}
+
+	locale, err := subscriptionLocale(alert.Locale)
+	if err != nil {
+		return err
+	}
+	alert.Locale = locale
+
+	verified, err := uc.emailVerifier.isVerified(alert.Email)
+	if err != nil {
+		return err
+	}
+
+	if err := uc.holdingRepo.SavePortfolioAlert(alert); err != nil {
+		return err
+	}
+
+	if !verified {
+		uc.emailVerifier.requestConfirmation(alert.Email, "PORTFOLIO", alert.Locale)
+	}
+
+	return nil
+}
@@ -0,0 +1,203 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	apiRepo "crypto-alerts/internal/repository/api"
+	dbRepo "crypto-alerts/internal/repository/db"
+	"log"
+	"sort"
+	"time"
+)
+
+type SendDigestsUseCase interface {
+	Execute(now time.Time) (int, error)
+}
+
+type sendDigestsUseCase struct {
+	alertRepo         dbRepo.AlertThresholdRepository
+	historyRepo       dbRepo.AlertHistoryRepository
+	settingsRepo      dbRepo.NotificationSettingsRepository
+	coinMarketCapRepo apiRepo.CoinMarketCapRepository
+	outboxRepo        dbRepo.OutboxRepository
+	digestHour        int
+}
+
+func NewSendDigestsUseCase(
+	alertRepo dbRepo.AlertThresholdRepository,
+	historyRepo dbRepo.AlertHistoryRepository,
+	settingsRepo dbRepo.NotificationSettingsRepository,
+	coinMarketCapRepo apiRepo.CoinMarketCapRepository,
+	outboxRepo dbRepo.OutboxRepository,
+	digestHour int,
+) SendDigestsUseCase {
+	return &sendDigestsUseCase{
+		alertRepo:         alertRepo,
+		historyRepo:       historyRepo,
+		settingsRepo:      settingsRepo,
+		coinMarketCapRepo: coinMarketCapRepo,
+		outboxRepo:        outboxRepo,
+		digestHour:        digestHour,
+	}
+}
+
+type digestGroup struct {
+	email      string
+	frequency  string
+	thresholds []*entity.AlertThreshold
+	since      time.Time
+}
+
+// Execute puts in the outbox every digest whose slot, in the subscriber's
+// time zone, has passed since it was last sent. It is safe to run often: a
+// digest goes out once per slot, and one falling in quiet hours stays due
+// until they end.
+func (uc *sendDigestsUseCase) Execute(now time.Time) (int, error) {
+	thresholds, err := uc.alertRepo.GetAllThresholds()
+	if err != nil {
+		log.Printf("Error getting thresholds from database: %v", err)
+		return 0, err
+	}
+
+	emailSet := make(map[string]bool)
+	for _, threshold := range thresholds {
+		if threshold.DigestFrequency != entity.DigestFrequencyNone {
+			emailSet[threshold.Email] = true
+		}
+	}
+	if len(emailSet) == 0 {
+		return 0, nil
+	}
+	emails := make([]string, 0, len(emailSet))
+	for email := range emailSet {
+		emails = append(emails, email)
+	}
+
+	settings, err := uc.settingsRepo.GetByEmails(emails)
+	if err != nil {
+		log.Printf("Error getting notification settings from database: %v", err)
+		return 0, err
+	}
+
+	groups := uc.dueGroups(thresholds, settings, now)
+	if len(groups) == 0 {
+		return 0, nil
+	}
+
+	symbolSet := make(map[string]bool)
+	for _, group := range groups {
+		for _, threshold := range group.thresholds {
+			symbolSet[threshold.CryptoSymbol] = true
+		}
+	}
+	symbols := make([]string, 0, len(symbolSet))
+	for symbol := range symbolSet {
+		symbols = append(symbols, symbol)
+	}
+
+	cryptoData, err := uc.coinMarketCapRepo.GetCryptoPrices(symbols)
+	if err != nil {
+		log.Printf("Error getting crypto prices: %v", err)
+		return 0, err
+	}
+
+	fearGreed, err := uc.coinMarketCapRepo.GetFearGreedIndex()
+	if err != nil {
+		log.Printf("Warning: Failed to get Fear & Greed Index: %v", err)
+		fearGreed = nil
+	}
+
+	sent := 0
+	for _, group := range groups {
+		alerts, err := uc.historyRepo.GetByEmailSince(group.email, group.since)
+		if err != nil {
+			log.Printf("Failed to load alert history for %s: %v", group.email, err)
+			continue
+		}
+
+		since := group.since
+		digest := pkg.BuildDigest(group.email, group.frequency, group.thresholds, cryptoData, alerts, fearGreed, &since, settings[group.email].Location())
+
+		body, err := pkg.FormatDigestEmailBody(digest)
+		if err != nil {
+			log.Printf("Failed to render %s digest for %s: %v", group.frequency, group.email, err)
+			continue
+		}
+
+		message := &entity.OutboxMessage{
+			Email:     group.email,
+			Subject:   pkg.FormatDigestEmailSubject(digest),
+			Body:      body.HTML,
+			TextBody:  body.Text,
+			CreatedAt: now,
+		}
+		if err := uc.outboxRepo.Enqueue(message, nil); err != nil {
+			log.Printf("Failed to enqueue %s digest for %s: %v", group.frequency, group.email, err)
+			continue
+		}
+
+		ids := make([]int64, 0, len(group.thresholds))
+		for _, threshold := range group.thresholds {
+			ids = append(ids, threshold.ID)
+		}
+		if err := uc.alertRepo.UpdateLastDigest(ids, now); err != nil {
+			log.Printf("Failed to record %s digest for %s: %v", group.frequency, group.email, err)
+		}
+
+		log.Printf("%s digest for %s with %d symbols and %d alerts added to the outbox", group.frequency, group.email, len(digest.Entries), len(alerts))
+		sent++
+	}
+
+	return sent, nil
+}
+
+// dueGroups groups active digest subscriptions by email and frequency and
+// keeps the groups not sent since their current slot, leaving out users in
+// quiet hours. A group never sent before covers the period preceding the
+// slot.
+func (uc *sendDigestsUseCase) dueGroups(
+	thresholds []*entity.AlertThreshold,
+	settings map[string]*entity.NotificationSettings,
+	now time.Time,
+) []*digestGroup {
+	byKey := make(map[string]*digestGroup)
+	var keys []string
+
+	for _, threshold := range thresholds {
+		if threshold.DigestFrequency == entity.DigestFrequencyNone || threshold.State != entity.AlertStateActive {
+			continue
+		}
+
+		key := threshold.Email + "/" + threshold.DigestFrequency
+		group, exists := byKey[key]
+		if !exists {
+			slot := entity.DigestSlot(threshold.DigestFrequency, uc.digestHour, now, settings[threshold.Email].Location())
+			previousSlot := slot.AddDate(0, 0, -1)
+			if threshold.DigestFrequency == entity.DigestFrequencyWeekly {
+				previousSlot = slot.AddDate(0, 0, -7)
+			}
+
+			group = &digestGroup{email: threshold.Email, frequency: threshold.DigestFrequency, since: previousSlot}
+			byKey[key] = group
+			keys = append(keys, key)
+		}
+
+		group.thresholds = append(group.thresholds, threshold)
+		if threshold.LastDigestAt != nil && threshold.LastDigestAt.After(group.since) {
+			group.since = *threshold.LastDigestAt
+		}
+	}
+
+	sort.Strings(keys)
+
+	var due []*digestGroup
+	for _, key := range keys {
+		group := byKey[key]
+		slot := entity.DigestSlot(group.frequency, uc.digestHour, now, settings[group.email].Location())
+		if group.since.Before(slot) && !settings[group.email].InQuietHours(now) {
+			due = append(due, group)
+		}
+	}
+
+	return due
+}
@@ -0,0 +1,123 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	apiRepo "crypto-alerts/internal/repository/api"
+	"crypto-alerts/internal/repository/db"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+	_ "time/tzdata"
+)
+
+type digestAlertRepo struct {
+	db.AlertThresholdRepository
+	thresholds []*entity.AlertThreshold
+}
+
+func (r *digestAlertRepo) GetAllThresholds() ([]*entity.AlertThreshold, error) {
+	return r.thresholds, nil
+}
+
+func (r *digestAlertRepo) UpdateLastDigest(ids []int64, sentAt time.Time) error {
+	for _, threshold := range r.thresholds {
+		for _, id := range ids {
+			if threshold.ID == id {
+				threshold.LastDigestAt = &sentAt
+			}
+		}
+	}
+	return nil
+}
+
+type digestHistoryRepo struct {
+	db.AlertHistoryRepository
+}
+
+func (digestHistoryRepo) GetByEmailSince(email string, since time.Time) ([]*entity.AlertHistory, error) {
+	return nil, nil
+}
+
+type digestSettingsRepo struct {
+	db.NotificationSettingsRepository
+	settings map[string]*entity.NotificationSettings
+}
+
+func (r digestSettingsRepo) GetByEmails(emails []string) (map[string]*entity.NotificationSettings, error) {
+	return r.settings, nil
+}
+
+type digestMarketRepo struct {
+	apiRepo.CoinMarketCapRepository
+}
+
+func (digestMarketRepo) GetCryptoPrices(symbols []string) (map[string]*entity.CryptoCurrency, error) {
+	return map[string]*entity.CryptoCurrency{"BTC": {Name: "Bitcoin", Price: 65000}}, nil
+}
+
+func (digestMarketRepo) GetFearGreedIndex() (*entity.FearGreedIndex, error) {
+	return nil, nil
+}
+
+type outboxRecorder struct {
+	db.OutboxRepository
+	emails []string
+}
+
+func (r *outboxRecorder) Enqueue(message *entity.OutboxMessage, history []*entity.AlertHistory) error {
+	r.emails = append(r.emails, message.Email)
+	return nil
+}
+
+func TestSendDigestsSlotsAndQuietHours(t *testing.T) {
+	lastDigest := func(value string) *time.Time {
+		parsed, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return &parsed
+	}
+	digest := func(id int64, email string, last *time.Time) *entity.AlertThreshold {
+		return &entity.AlertThreshold{
+			ID:              id,
+			Email:           email,
+			CryptoSymbol:    "BTC",
+			State:           entity.AlertStateActive,
+			DigestFrequency: entity.DigestFrequencyDaily,
+			LastDigestAt:    last,
+		}
+	}
+
+	alertRepo := &digestAlertRepo{thresholds: []*entity.AlertThreshold{
+		digest(1, "utc@example.com", lastDigest("2026-03-15T08:30:00Z")),
+		// 08:00 in São Paulo is 11:00 UTC
+		digest(2, "saopaulo@example.com", lastDigest("2026-03-15T11:05:00Z")),
+		digest(3, "quiet@example.com", nil),
+	}}
+	settingsRepo := digestSettingsRepo{settings: map[string]*entity.NotificationSettings{
+		"saopaulo@example.com": {Email: "saopaulo@example.com", TimeZone: "America/Sao_Paulo"},
+		"quiet@example.com":    {Email: "quiet@example.com", QuietHoursStart: "22:00", QuietHoursEnd: "10:00"},
+	}}
+	outbox := &outboxRecorder{}
+	uc := NewSendDigestsUseCase(alertRepo, digestHistoryRepo{}, settingsRepo, digestMarketRepo{}, outbox, 8)
+
+	run := func(now string) []string {
+		outbox.emails = nil
+		if _, err := uc.Execute(*lastDigest(now)); err != nil {
+			t.Fatalf("Execute at %s: %v", now, err)
+		}
+		sort.Strings(outbox.emails)
+		return outbox.emails
+	}
+
+	if got, want := run("2026-03-16T09:00:00Z"), []string{"utc@example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("at 09:00 UTC enqueued %v, want %v", got, want)
+	}
+	if got, want := run("2026-03-16T11:30:00Z"), []string{"quiet@example.com", "saopaulo@example.com"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("at 11:30 UTC enqueued %v, want %v", got, want)
+	}
+	if got := run("2026-03-16T12:00:00Z"); len(got) != 0 {
+		t.Errorf("enqueued %v again within the same slot", got)
+	}
+}
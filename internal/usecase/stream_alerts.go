@@ -0,0 +1,45 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+)
+
+// streamReplayPageSize caps the history entries read at once when a client
+// resumes the stream.
+const streamReplayPageSize = 200
+
+type StreamAlertsUseCase interface {
+	Subscribe(email string, symbol string) *pkg.AlertSubscription
+	Unsubscribe(subscription *pkg.AlertSubscription)
+	Replay(subscription *pkg.AlertSubscription, afterID int64) ([]*entity.AlertHistory, error)
+}
+
+type streamAlertsUseCase struct {
+	historyRepo db.AlertHistoryRepository
+	stream      *pkg.AlertStream
+}
+
+func NewStreamAlertsUseCase(historyRepo db.AlertHistoryRepository, stream *pkg.AlertStream) StreamAlertsUseCase {
+	return &streamAlertsUseCase{
+		historyRepo: historyRepo,
+		stream:      stream,
+	}
+}
+
+// Subscribe starts buffering live alerts, which should happen before the
+// history is replayed so nothing recorded in between is missed.
+func (uc *streamAlertsUseCase) Subscribe(email string, symbol string) *pkg.AlertSubscription {
+	return uc.stream.Subscribe(email, symbol)
+}
+
+func (uc *streamAlertsUseCase) Unsubscribe(subscription *pkg.AlertSubscription) {
+	uc.stream.Unsubscribe(subscription)
+}
+
+// Replay returns the next page of alerts recorded after afterID that match
+// the subscription, an empty page meaning the client has caught up.
+func (uc *streamAlertsUseCase) Replay(subscription *pkg.AlertSubscription, afterID int64) ([]*entity.AlertHistory, error) {
+	return uc.historyRepo.GetAfter(afterID, subscription.Email, subscription.Symbol, streamReplayPageSize)
+}
@@ -0,0 +1,54 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/pkg"
+	"crypto-alerts/internal/repository/db"
+	"log"
+	"time"
+)
+
+type UnsubscribeUseCase interface {
+	Verify(token string) (*entity.AlertAction, error)
+	Execute(token string) (*entity.AlertAction, error)
+}
+
+type unsubscribeUseCase struct {
+	suppressionRepo db.SuppressionRepository
+	actionSigner    *pkg.ActionSigner
+}
+
+func NewUnsubscribeUseCase(suppressionRepo db.SuppressionRepository, actionSigner *pkg.ActionSigner) UnsubscribeUseCase {
+	return &unsubscribeUseCase{
+		suppressionRepo: suppressionRepo,
+		actionSigner:    actionSigner,
+	}
+}
+
+func (uc *unsubscribeUseCase) Verify(token string) (*entity.AlertAction, error) {
+	action, err := uc.actionSigner.Verify(token, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if action.Action != entity.AlertActionUnsubscribeAll {
+		return nil, pkg.ErrInvalidActionToken
+	}
+	return action, nil
+}
+
+// Execute adds the address of a signed unsubscribe link to the suppression
+// list, which the notifier checks before every send.
+func (uc *unsubscribeUseCase) Execute(token string) (*entity.AlertAction, error) {
+	action, err := uc.Verify(token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := uc.suppressionRepo.Suppress(action.Email, db.SuppressionReasonUnsubscribe); err != nil {
+		return nil, err
+	}
+
+	log.Printf("%s unsubscribed from all emails", action.Email)
+
+	return action, nil
+}
@@ -0,0 +1,38 @@
+package usecase
+
+import (
+	"crypto-alerts/internal/entity"
+	"crypto-alerts/internal/repository/db"
+	"fmt"
+)
+
+type UpdateAlertStateUseCase interface {
+	Execute(id int64, email string, state string) error
+}
+
+type updateAlertStateUseCase struct {
+	alertRepo db.AlertThresholdRepository
+}
+
+func NewUpdateAlertStateUseCase(alertRepo db.AlertThresholdRepository) UpdateAlertStateUseCase {
+	return &updateAlertStateUseCase{
+		alertRepo: alertRepo,
+	}
+}
+
+// Execute only lets users pause and resume alerts; triggered and expired are
+// terminal states set by the scan and are rejected with
+// db.ErrInvalidStateTransition.
+func (uc *updateAlertStateUseCase) Execute(id int64, email string, state string) error {
+	if id == 0 {
+		return fmt.Errorf("id is required")
+	}
+	if email == "" {
+		return fmt.Errorf("email is required")
+	}
+	if _, ok := entity.AlertStateTransitions[state]; !ok {
+		return fmt.Errorf("state must be %s or %s", entity.AlertStateActive, entity.AlertStatePaused)
+	}
+
+	return uc.alertRepo.UpdateState(id, email, state)
+}
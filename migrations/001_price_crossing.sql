@@ -0,0 +1,4 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS last_observed_price DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS last_cross_direction VARCHAR(4),
+    ADD COLUMN IF NOT EXISTS last_crossed_at TIMESTAMP;
@@ -0,0 +1,9 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS state VARCHAR(16) NOT NULL DEFAULT 'active',
+    ADD COLUMN IF NOT EXISTS one_shot BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS max_triggers INTEGER,
+    ADD COLUMN IF NOT EXISTS trigger_count INTEGER NOT NULL DEFAULT 0,
+    ADD COLUMN IF NOT EXISTS starts_at TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS expires_at TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS idx_user_crypto_thresholds_email ON user_crypto_thresholds (email);
@@ -0,0 +1,3 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS composite_rule JSONB,
+    ADD COLUMN IF NOT EXISTS composite_rule_enabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,3 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS custom_expression TEXT,
+    ADD COLUMN IF NOT EXISTS custom_expression_enabled BOOLEAN NOT NULL DEFAULT FALSE;
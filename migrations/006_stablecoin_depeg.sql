@@ -0,0 +1,6 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS depeg_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS depeg_peg_price DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS depeg_band_bps DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS depeg_sustained_scans INTEGER NOT NULL DEFAULT 1,
+    ADD COLUMN IF NOT EXISTS depeg_consecutive_scans INTEGER NOT NULL DEFAULT 0;
@@ -0,0 +1,6 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS range_lookback_days INTEGER NOT NULL DEFAULT 90,
+    ADD COLUMN IF NOT EXISTS new_high_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS new_low_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS drawdown_percent DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS drawdown_enabled BOOLEAN NOT NULL DEFAULT FALSE;
@@ -0,0 +1,6 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS volatility_enabled BOOLEAN NOT NULL DEFAULT FALSE,
+    ADD COLUMN IF NOT EXISTS volatility_short_window INTEGER NOT NULL DEFAULT 7,
+    ADD COLUMN IF NOT EXISTS volatility_long_window INTEGER NOT NULL DEFAULT 90,
+    ADD COLUMN IF NOT EXISTS volatility_multiple DOUBLE PRECISION,
+    ADD COLUMN IF NOT EXISTS volatility_regime_active BOOLEAN NOT NULL DEFAULT FALSE;
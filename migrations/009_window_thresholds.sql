@@ -0,0 +1,53 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS window_thresholds JSONB NOT NULL DEFAULT '[]';
+
+UPDATE user_crypto_thresholds t
+SET window_thresholds = COALESCE((
+    SELECT jsonb_agg(jsonb_build_object(
+        'window', w.period,
+        'direction', w.direction,
+        'percent', w.percent,
+        'enabled', w.enabled
+    ))
+    FROM (VALUES
+        ('1h', 'up', t.threshold_up_1h_percent, t.threshold_up_1h_enabled),
+        ('1h', 'down', t.threshold_down_1h_percent, t.threshold_down_1h_enabled),
+        ('24h', 'up', t.threshold_up_24h_percent, t.threshold_up_24h_enabled),
+        ('24h', 'down', t.threshold_down_24h_percent, t.threshold_down_24h_enabled),
+        ('7d', 'up', t.threshold_up_7d_percent, t.threshold_up_7d_enabled),
+        ('7d', 'down', t.threshold_down_7d_percent, t.threshold_down_7d_enabled),
+        ('30d', 'up', t.threshold_up_30d_percent, t.threshold_up_30d_enabled),
+        ('30d', 'down', t.threshold_down_30d_percent, t.threshold_down_30d_enabled),
+        ('60d', 'up', t.threshold_up_60d_percent, t.threshold_up_60d_enabled),
+        ('60d', 'down', t.threshold_down_60d_percent, t.threshold_down_60d_enabled),
+        ('90d', 'up', t.threshold_up_90d_percent, t.threshold_up_90d_enabled),
+        ('90d', 'down', t.threshold_down_90d_percent, t.threshold_down_90d_enabled)
+    ) AS w(period, direction, percent, enabled)
+    WHERE w.percent IS NOT NULL
+), '[]');
+
+ALTER TABLE user_crypto_thresholds
+    DROP COLUMN IF EXISTS threshold_up_1h_percent,
+    DROP COLUMN IF EXISTS threshold_up_1h_enabled,
+    DROP COLUMN IF EXISTS threshold_down_1h_percent,
+    DROP COLUMN IF EXISTS threshold_down_1h_enabled,
+    DROP COLUMN IF EXISTS threshold_up_24h_percent,
+    DROP COLUMN IF EXISTS threshold_up_24h_enabled,
+    DROP COLUMN IF EXISTS threshold_down_24h_percent,
+    DROP COLUMN IF EXISTS threshold_down_24h_enabled,
+    DROP COLUMN IF EXISTS threshold_up_7d_percent,
+    DROP COLUMN IF EXISTS threshold_up_7d_enabled,
+    DROP COLUMN IF EXISTS threshold_down_7d_percent,
+    DROP COLUMN IF EXISTS threshold_down_7d_enabled,
+    DROP COLUMN IF EXISTS threshold_up_30d_percent,
+    DROP COLUMN IF EXISTS threshold_up_30d_enabled,
+    DROP COLUMN IF EXISTS threshold_down_30d_percent,
+    DROP COLUMN IF EXISTS threshold_down_30d_enabled,
+    DROP COLUMN IF EXISTS threshold_up_60d_percent,
+    DROP COLUMN IF EXISTS threshold_up_60d_enabled,
+    DROP COLUMN IF EXISTS threshold_down_60d_percent,
+    DROP COLUMN IF EXISTS threshold_down_60d_enabled,
+    DROP COLUMN IF EXISTS threshold_up_90d_percent,
+    DROP COLUMN IF EXISTS threshold_up_90d_enabled,
+    DROP COLUMN IF EXISTS threshold_down_90d_percent,
+    DROP COLUMN IF EXISTS threshold_down_90d_enabled;
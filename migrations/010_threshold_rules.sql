@@ -0,0 +1,34 @@
+CREATE TABLE IF NOT EXISTS user_crypto_threshold_rules (
+    id BIGSERIAL PRIMARY KEY,
+    threshold_id BIGINT NOT NULL,
+    kind VARCHAR(32) NOT NULL,
+    period VARCHAR(16) NOT NULL DEFAULT '',
+    direction VARCHAR(8) NOT NULL,
+    value DOUBLE PRECISION NOT NULL,
+    enabled BOOLEAN NOT NULL DEFAULT TRUE
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_crypto_threshold_rules_threshold_id
+    ON user_crypto_threshold_rules (threshold_id);
+
+INSERT INTO user_crypto_threshold_rules (threshold_id, kind, period, direction, value, enabled)
+SELECT t.id, 'window', w->>'window', w->>'direction', (w->>'percent')::DOUBLE PRECISION, COALESCE((w->>'enabled')::BOOLEAN, FALSE)
+FROM user_crypto_thresholds t
+CROSS JOIN LATERAL jsonb_array_elements(t.window_thresholds) AS w;
+
+INSERT INTO user_crypto_threshold_rules (threshold_id, kind, period, direction, value, enabled)
+SELECT id, 'target_price', '', 'up', target_price_up, target_price_up_enabled
+FROM user_crypto_thresholds
+WHERE target_price_up IS NOT NULL;
+
+INSERT INTO user_crypto_threshold_rules (threshold_id, kind, period, direction, value, enabled)
+SELECT id, 'target_price', '', 'down', target_price_down, target_price_down_enabled
+FROM user_crypto_thresholds
+WHERE target_price_down IS NOT NULL;
+
+ALTER TABLE user_crypto_thresholds
+    DROP COLUMN IF EXISTS window_thresholds,
+    DROP COLUMN IF EXISTS target_price_up,
+    DROP COLUMN IF EXISTS target_price_up_enabled,
+    DROP COLUMN IF EXISTS target_price_down,
+    DROP COLUMN IF EXISTS target_price_down_enabled;
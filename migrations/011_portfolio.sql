@@ -0,0 +1,23 @@
+CREATE TABLE IF NOT EXISTS user_holdings (
+    id BIGSERIAL PRIMARY KEY,
+    email VARCHAR(255) NOT NULL,
+    crypto_symbol VARCHAR(20) NOT NULL,
+    quantity DOUBLE PRECISION NOT NULL,
+    average_entry_price DOUBLE PRECISION NOT NULL,
+    pnl_percent_up DOUBLE PRECISION,
+    pnl_percent_down DOUBLE PRECISION,
+    pnl_amount_up DOUBLE PRECISION,
+    pnl_amount_down DOUBLE PRECISION,
+    last_observed_price DOUBLE PRECISION,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW(),
+    UNIQUE (email, crypto_symbol)
+);
+
+CREATE TABLE IF NOT EXISTS user_portfolio_alerts (
+    email VARCHAR(255) PRIMARY KEY,
+    value_above DOUBLE PRECISION,
+    value_below DOUBLE PRECISION,
+    last_observed_value DOUBLE PRECISION,
+    updated_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
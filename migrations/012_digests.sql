@@ -0,0 +1,18 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS digest_frequency VARCHAR(10) NOT NULL DEFAULT '',
+    ADD COLUMN IF NOT EXISTS last_digest_at TIMESTAMP;
+
+CREATE TABLE IF NOT EXISTS alert_history (
+    id BIGSERIAL PRIMARY KEY,
+    threshold_id BIGINT NOT NULL DEFAULT 0,
+    email VARCHAR(255) NOT NULL,
+    symbol VARCHAR(41) NOT NULL,
+    period VARCHAR(20) NOT NULL,
+    direction VARCHAR(8) NOT NULL,
+    price DOUBLE PRECISION NOT NULL,
+    subject TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_alert_history_email_created_at
+    ON alert_history (email, created_at);
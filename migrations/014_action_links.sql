@@ -0,0 +1,2 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS snoozed_until TIMESTAMP;
@@ -0,0 +1,12 @@
+CREATE TABLE IF NOT EXISTS verified_emails (
+    email VARCHAR(255) PRIMARY KEY,
+    verified_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+-- Subscriptions created before double opt-in keep mailing their owners
+INSERT INTO verified_emails (email)
+SELECT DISTINCT LOWER(email) FROM user_crypto_thresholds
+ON CONFLICT (email) DO NOTHING;
+
+CREATE INDEX IF NOT EXISTS idx_user_crypto_thresholds_pending
+    ON user_crypto_thresholds (created_at) WHERE state = 'pending';
@@ -0,0 +1,2 @@
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'pt-BR';
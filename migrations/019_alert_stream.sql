@@ -0,0 +1,2 @@
+ALTER TABLE alert_history
+    ADD COLUMN IF NOT EXISTS message JSONB;
@@ -0,0 +1,17 @@
+ALTER TABLE outbox_messages
+    ADD COLUMN IF NOT EXISTS locked_until TIMESTAMP,
+    ADD COLUMN IF NOT EXISTS redrive_count INTEGER NOT NULL DEFAULT 0;
+
+CREATE INDEX IF NOT EXISTS idx_outbox_messages_sending
+    ON outbox_messages (locked_until) WHERE status = 'sending';
+
+CREATE TABLE IF NOT EXISTS outbox_failures (
+    id BIGSERIAL PRIMARY KEY,
+    message_id BIGINT NOT NULL REFERENCES outbox_messages (id) ON DELETE CASCADE,
+    attempt INTEGER NOT NULL,
+    error TEXT NOT NULL,
+    failed_at TIMESTAMP NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX IF NOT EXISTS idx_outbox_failures_message_id
+    ON outbox_failures (message_id);
@@ -0,0 +1,9 @@
+-- Holdings and portfolio alerts created before they were gated by double
+-- opt-in keep mailing their owners
+INSERT INTO verified_emails (email)
+SELECT DISTINCT LOWER(email) FROM user_holdings
+ON CONFLICT (email) DO NOTHING;
+
+INSERT INTO verified_emails (email)
+SELECT DISTINCT LOWER(email) FROM user_portfolio_alerts
+ON CONFLICT (email) DO NOTHING;
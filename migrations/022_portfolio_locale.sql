@@ -0,0 +1,5 @@
+ALTER TABLE user_holdings
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'pt-BR';
+
+ALTER TABLE user_portfolio_alerts
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'pt-BR';
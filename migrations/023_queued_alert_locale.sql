@@ -0,0 +1,2 @@
+ALTER TABLE queued_alerts
+    ADD COLUMN IF NOT EXISTS locale VARCHAR(10) NOT NULL DEFAULT 'pt-BR';
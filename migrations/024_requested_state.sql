@@ -0,0 +1,4 @@
+-- State a threshold held back by double opt-in moves to once its address is
+-- confirmed
+ALTER TABLE user_crypto_thresholds
+    ADD COLUMN IF NOT EXISTS requested_state VARCHAR(16);
@@ -0,0 +1,5 @@
+ALTER TABLE outbox_messages
+    ADD COLUMN IF NOT EXISTS text_body TEXT NOT NULL DEFAULT '';
+
+ALTER TABLE queued_alerts
+    ADD COLUMN IF NOT EXISTS text_body TEXT NOT NULL DEFAULT '';
@@ -0,0 +1,10 @@
+-- Rules left behind by thresholds deleted before the foreign key existed
+DELETE FROM user_crypto_threshold_rules r
+WHERE NOT EXISTS (SELECT 1 FROM user_crypto_thresholds t WHERE t.id = r.threshold_id);
+
+ALTER TABLE user_crypto_threshold_rules
+    DROP CONSTRAINT IF EXISTS user_crypto_threshold_rules_threshold_id_fkey;
+
+ALTER TABLE user_crypto_threshold_rules
+    ADD CONSTRAINT user_crypto_threshold_rules_threshold_id_fkey
+    FOREIGN KEY (threshold_id) REFERENCES user_crypto_thresholds (id) ON DELETE CASCADE;